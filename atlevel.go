@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// noForcedLevel is the forcedMinLevel without any AtLevel call running.
+const noForcedLevel = int32(zapcore.FatalLevel + 1)
+
+var (
+	// forcedLevelsMu serializes the updates of forcedLevels.
+	forcedLevelsMu sync.Mutex
+	// forcedLevels counts the running AtLevel calls by level.
+	forcedLevels = map[zapcore.Level]int{}
+	// forcedMinLevel is the lowest level of forcedLevels, so that the
+	// cores of the package read it without locking.
+	forcedMinLevel = noForcedLevel
+)
+
+// AtLevel runs f with a child of l logging at lvl and above, regardless of
+// the level of l, such as the one set with SetLogLevel, and of SetNamedLevel,
+// for debugging a block of code without affecting the other goroutines.
+// Once f returns, the child, and its own children, are back to the level of
+// l. This applies to the loggers built by this package; any other is passed
+// to f as is.
+func (l *Logger) AtLevel(lvl zapcore.Level, f func(*Logger)) {
+	defer forceLevel(lvl)()
+
+	core, ok := l.Desugar().Core().(*namedLevelCore)
+	if !ok {
+		f(l)
+		return
+	}
+	forced := core.atLevel(lvl)
+	child := l.child(l.Desugar().WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return forced
+	})).Sugar())
+	child.levels = forced
+	f(child)
+}
+
+// forceLevel enables lvl in the cores of the package until release is
+// called, for the child of an AtLevel call to be written.
+func forceLevel(lvl zapcore.Level) (release func()) {
+	update := func(delta int) {
+		forcedLevelsMu.Lock()
+		defer forcedLevelsMu.Unlock()
+		if forcedLevels[lvl] += delta; forcedLevels[lvl] == 0 {
+			delete(forcedLevels, lvl)
+		}
+		min := noForcedLevel
+		for l := range forcedLevels {
+			if int32(l) < min {
+				min = int32(l)
+			}
+		}
+		atomic.StoreInt32(&forcedMinLevel, min)
+	}
+	update(1)
+	return func() { update(-1) }
+}
+
+// forcedEnabled reports whether an AtLevel call enables lvl.
+func forcedEnabled(lvl zapcore.Level) bool {
+	return int32(lvl) >= atomic.LoadInt32(&forcedMinLevel)
+}
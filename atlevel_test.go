@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger_AtLevel(t *testing.T) {
+	defer ClearNamedLevel("txmanager")
+	var buf bytes.Buffer
+	l := NewWriterLogger(&buf, zapcore.InfoLevel, true).Named("txmanager")
+	SetNamedLevel("txmanager", zapcore.WarnLevel)
+
+	var escaped *Logger
+	before := testutil.ToFloat64(getCounters().debugLines)
+	l.AtLevel(zapcore.DebugLevel, func(l *Logger) {
+		assert.True(t, l.DebugEnabled())
+		l.Debugw("forced")
+		l.With("key", "value").Named("broadcaster").Debugw("forced child")
+		l.Tracew("below the forced level")
+		escaped = l
+	})
+	l.Debugw("outside")
+	l.Infow("overridden")
+	escaped.Debugw("after return")
+	assert.False(t, escaped.DebugEnabled())
+	require.NoError(t, l.Sync())
+	assert.Equal(t, before+2, testutil.ToFloat64(getCounters().debugLines))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"msg":"forced"`)
+	assert.Contains(t, lines[1], `"logger":"txmanager.broadcaster"`)
+	assert.Contains(t, lines[1], `"msg":"forced child","key":"value"`)
+	assert.Equal(t, noForcedLevel, forcedMinLevel)
+}
+
+func TestLogger_AtLevel_OtherGoroutines(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWriterLogger(&buf, zapcore.InfoLevel, true)
+
+	l.AtLevel(zapcore.DebugLevel, func(*Logger) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.False(t, l.DebugEnabled())
+			l.Debugw("other goroutine")
+		}()
+		wg.Wait()
+	})
+	require.NoError(t, l.Sync())
+	assert.Zero(t, buf.Len())
+}
+
+func TestLogger_AtLevel_NotBuiltByPackage(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := NewLogger(zap.New(core))
+
+	l.AtLevel(zapcore.DebugLevel, func(child *Logger) {
+		assert.Same(t, l, child)
+		child.Debugw("filtered")
+	})
+	assert.Zero(t, logs.Len())
+}
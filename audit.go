@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AuditActor is the key of the actor of the events logged by Audit.
+const AuditActor = "actor"
+
+var (
+	// auditMu guards auditLogger and closeAuditOutputs.
+	auditMu           sync.RWMutex
+	auditLogger       = newAuditLogger(zapcore.Lock(os.Stderr))
+	closeAuditOutputs = func() {}
+)
+
+// newAuditLogger returns a logger writing every entry to ws, whatever its
+// level, in the schema of Audit.
+func newAuditLogger(ws zapcore.WriteSyncer) *zap.SugaredLogger {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		TimeKey:        "timestamp",
+		MessageKey:     "event",
+		EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	})
+	always := zap.LevelEnablerFunc(func(zapcore.Level) bool { return true })
+	return zap.New(zapcore.NewCore(enc, ws, always)).Sugar()
+}
+
+// SetAuditOutputs sets the output paths, as accepted by zap.Open, the
+// events logged by Audit are written to, stderr by default. The outputs
+// previously set are closed.
+func SetAuditOutputs(paths ...string) error {
+	ws, closeOutputs, err := zap.Open(paths...)
+	if err != nil {
+		return err
+	}
+	auditMu.Lock()
+	closePrevious := closeAuditOutputs
+	auditLogger, closeAuditOutputs = newAuditLogger(ws), closeOutputs
+	auditMu.Unlock()
+	closePrevious()
+	return nil
+}
+
+// Audit logs an event of the audit trail, with any additional given
+// information, to the outputs set with SetAuditOutputs. Events are always
+// written, whatever the level of the package, as JSON objects holding the
+// "timestamp", the "event" and the "actor", taken from the actor key value
+// pair if given and empty otherwise, followed by the other fields. Events
+// are counted by event in the log_audit_events_total counter, so they
+// should be of a fixed set, such as "user.login".
+func Audit(event string, keysAndValues ...interface{}) {
+	actor, kv := splitAuditActor(redact(keysAndValues))
+	auditMu.RLock()
+	auditLogger.Infow(event, append(actor, kv...)...)
+	auditMu.RUnlock()
+	getCounters().auditEvents.WithLabelValues(event).Inc()
+}
+
+// splitAuditActor returns the actor key value pair, or field, of kv, or an
+// empty actor, and the rest of kv.
+func splitAuditActor(kv []interface{}) (actor, rest []interface{}) {
+	for i := 0; i < len(kv); i++ {
+		switch k := kv[i].(type) {
+		case zap.Field:
+			// Strongly typed fields stand on their own, without a value.
+			if k.Key == AuditActor {
+				return kv[i : i+1 : i+1], append(kv[:i:i], kv[i+1:]...)
+			}
+			continue
+		case string:
+			if k == AuditActor && i+1 < len(kv) {
+				return kv[i : i+2 : i+2], append(kv[:i:i], kv[i+2:]...)
+			}
+		}
+		i++
+	}
+	return []interface{}{AuditActor, ""}, kv
+}
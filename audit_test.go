@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAudit(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	defer func() { require.NoError(t, SetAuditOutputs("stderr")) }()
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.jsonl")
+	require.NoError(t, SetAuditOutputs(path))
+
+	SetLogLevel(zapcore.FatalLevel)
+	before := testutil.ToFloat64(getCounters().auditEvents.WithLabelValues("user.login"))
+	Audit("user.login", "ip", "10.0.0.1", AuditActor, "alice")
+	Audit("user.login", zap.String(AuditActor, "bob"))
+	Audit("config.reloaded")
+	assert.Equal(t, before+2, testutil.ToFloat64(getCounters().auditEvents.WithLabelValues("user.login")))
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 3)
+	assert.Regexp(t, `^\{"timestamp":"[^"]+","event":"user.login","actor":"alice","ip":"10.0.0.1"\}$`, lines[0])
+	assert.Regexp(t, `^\{"timestamp":"[^"]+","event":"user.login","actor":"bob"\}$`, lines[1])
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &entry))
+	assert.Equal(t, "config.reloaded", entry["event"])
+	assert.Equal(t, "", entry[AuditActor])
+}
+
+func TestSetAuditOutputs_Invalid(t *testing.T) {
+	assert.Error(t, SetAuditOutputs("unknown://audit"))
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"bufio"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// bufferedURI returns a buffered: URI for the output path, carrying the
+// buffer size and flush interval as query parameters.
+func bufferedURI(path string, size int, flushInterval time.Duration) string {
+	u := url.URL{
+		Scheme: "buffered",
+		RawQuery: url.Values{
+			"path":          {path},
+			"size":          {strconv.Itoa(size)},
+			"flushInterval": {flushInterval.String()},
+		}.Encode(),
+	}
+	return u.String()
+}
+
+// bufferedSink buffers the writes to the sink of an output path in memory,
+// writing them out when the buffer is full, every flushInterval, and on
+// Sync and Close.
+type bufferedSink struct {
+	mu    sync.Mutex
+	ws    zapcore.WriteSyncer
+	buf   *bufio.Writer
+	close func()
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+func newBufferedSink(u *url.URL) (zap.Sink, error) {
+	query := u.Query()
+	size, err := strconv.Atoi(query.Get("size"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid size for buffered output")
+	}
+	flushInterval, err := time.ParseDuration(query.Get("flushInterval"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid flushInterval for buffered output")
+	}
+	if flushInterval <= 0 {
+		return nil, errors.Errorf("invalid flushInterval for buffered output: %s is not positive", flushInterval)
+	}
+	ws, closeOutput, err := zap.Open(query.Get("path"))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &bufferedSink{
+		ws:    ws,
+		buf:   bufio.NewWriterSize(ws, size),
+		close: closeOutput,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.flushLoop(flushInterval)
+	return s, nil
+}
+
+func (s *bufferedSink) flushLoop(flushInterval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			_ = s.buf.Flush()
+			s.mu.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *bufferedSink) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(b)
+}
+
+func (s *bufferedSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	return s.ws.Sync()
+}
+
+// Close stops the periodic flushes, and flushes the buffer one last time
+// before closing the sink of the output path.
+func (s *bufferedSink) Close() error {
+	close(s.stop)
+	<-s.done
+	err := s.Sync()
+	s.close()
+	return err
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithBufferedWrites(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.jsonl")
+	read := func() string {
+		b, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	l, err := NewProductionLogger(dir, false, zapcore.InfoLevel, true, WithBufferedWrites(4096, time.Hour))
+	require.NoError(t, err)
+	l.Infow("buffered", "key", "value")
+	assert.Empty(t, read(), "the entry should still be buffered")
+	_ = l.Sync()
+	assert.Contains(t, read(), `"msg":"buffered","key":"value"`)
+
+	l, err = NewProductionLogger(dir, true, zapcore.InfoLevel, true, WithBufferedWrites(4096, 10*time.Millisecond))
+	require.NoError(t, err)
+	l.Infow("flushed periodically")
+	require.Eventually(t, func() bool {
+		return strings.Contains(read(), "flushed periodically")
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWithBufferedWrites_SkipsPrettyConsole(t *testing.T) {
+	config := productionConfig(false, zapcore.InfoLevel)
+	config.OutputPaths = append(config.OutputPaths, "prettyjson://stdout", "/var/log/log.jsonl")
+	applyOptions(&config, []Option{WithBufferedWrites(1024, time.Second)})
+
+	assert.Equal(t, "pretty://console", config.OutputPaths[0])
+	assert.Equal(t, "prettyjson://stdout", config.OutputPaths[1])
+	u, err := url.Parse(config.OutputPaths[2])
+	require.NoError(t, err)
+	assert.Equal(t, "buffered", u.Scheme)
+	assert.Equal(t, "/var/log/log.jsonl", u.Query().Get("path"))
+}
+
+func TestNewBufferedSink_Invalid(t *testing.T) {
+	u, err := url.Parse(bufferedURI("stderr", 1024, 0))
+	require.NoError(t, err)
+	_, err = newBufferedSink(u)
+	assert.EqualError(t, err, "invalid flushInterval for buffered output: 0s is not positive")
+}
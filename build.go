@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// route is a set of output paths written the entries enab enables, of
+// those enabled by the level of the logger. A nil enab enables every level.
+type route struct {
+	paths []string
+	enab  zapcore.LevelEnabler
+}
+
+// buildLogger builds a Logger from config, as config.Build does with the
+// buildOptions of the package, but keeping hold of the sinks it opens so
+// that Close can release them.
+func buildLogger(config zap.Config, opts ...zap.Option) (*Logger, error) {
+	return buildRoutedLogger(config, []route{{paths: config.OutputPaths}}, opts...)
+}
+
+// routeRecentPaths moves the recent:// output paths of routes to a route of
+// their own, so that they keep every entry the logger writes, whichever
+// route they were listed in.
+func routeRecentPaths(routes []route) []route {
+	var recentPaths []string
+	routed := make([]route, 0, len(routes)+1)
+	for _, r := range routes {
+		var paths []string
+		for _, path := range r.paths {
+			if isRecentPath(path) {
+				recentPaths = append(recentPaths, path)
+			} else {
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) > 0 {
+			routed = append(routed, route{paths: paths, enab: r.enab})
+		}
+	}
+	if len(recentPaths) > 0 {
+		routed = append(routed, route{paths: recentPaths})
+	}
+	return routed
+}
+
+// newEncoder returns the encoder of config.Encoding: the json and console
+// encodings of zap, and the stackdriver and cloudwatch ones of the package.
+func newEncoder(config zap.Config) (zapcore.Encoder, error) {
+	config.EncoderConfig.EncodeLevel = withTraceLevel(config.EncoderConfig.EncodeLevel)
+	switch config.Encoding {
+	case "json":
+		return zapcore.NewJSONEncoder(config.EncoderConfig), nil
+	case "console":
+		return zapcore.NewConsoleEncoder(config.EncoderConfig), nil
+	}
+	if projectID, ok := stackdriverProjectID(config.Encoding); ok {
+		return newStackdriverEncoder(config.EncoderConfig, projectID), nil
+	}
+	if namespace, ok := cloudWatchNamespace(config.Encoding); ok {
+		return newCloudWatchEncoder(config.EncoderConfig, namespace), nil
+	}
+	return nil, errors.Errorf("unsupported encoding %q", config.Encoding)
+}
+
+// buildRoutedLogger is like buildLogger, except entries are written to the
+// routes that enable them instead of to config.OutputPaths, through cores
+// composed with zapcore.NewTee.
+func buildRoutedLogger(config zap.Config, routes []route, opts ...zap.Option) (*Logger, error) {
+	enc, err := newEncoder(config)
+	if err != nil {
+		return nil, err
+	}
+	if config.Level == (zap.AtomicLevel{}) {
+		return nil, errors.New("missing Level")
+	}
+
+	routes = routeRecentPaths(routes)
+	levels := namedLevelEnabler{base: config.Level}
+
+	var closers []func()
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+	var cores []zapcore.Core
+	for _, r := range routes {
+		sink, closeSink, err := zap.Open(r.paths...)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		closers = append(closers, closeSink)
+		enab := zapcore.LevelEnabler(levels)
+		if r.enab != nil {
+			routeEnab := r.enab
+			enab = zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+				return routeEnab.Enabled(lvl) && levels.Enabled(lvl)
+			})
+		}
+		cores = append(cores, newWriteErrorCountingCore(zapcore.NewCore(enc.Clone(), sink, enab)))
+	}
+	errorOutput, closeErrorOutputs, err := zap.Open(config.ErrorOutputPaths...)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	closers = append(closers, closeErrorOutputs)
+
+	zopts := []zap.Option{zap.ErrorOutput(errorOutput)}
+	if config.Development {
+		zopts = append(zopts, zap.Development())
+	}
+	if !config.DisableCaller {
+		zopts = append(zopts, zap.AddCaller())
+	}
+	if s := config.Sampling; s != nil {
+		zopts = append(zopts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			var samplerOpts []zapcore.SamplerOption
+			if s.Hook != nil {
+				samplerOpts = append(samplerOpts, zapcore.SamplerHook(s.Hook))
+			}
+			return newSampler(core, time.Second, s.Initial, s.Thereafter, samplerOpts...)
+		}))
+	}
+	if len(config.InitialFields) > 0 {
+		keys := make([]string, 0, len(config.InitialFields))
+		for k := range config.InitialFields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fields := make([]zap.Field, 0, len(keys))
+		for _, k := range keys {
+			fields = append(fields, zap.Any(k, config.InitialFields[k]))
+		}
+		zopts = append(zopts, zap.Fields(fields...))
+	}
+	zopts = append(append(zopts, buildOptions(config)...), opts...)
+	// Outermost, so that the logger reports the levels of its names.
+	zopts = append(zopts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newNamedLevelCore(core, levels)
+	}))
+
+	l := NewLogger(zap.New(zapcore.NewTee(cores...), zopts...))
+	l.closer = &closer{close: closeAll}
+	l.errorOutput = errorOutput
+	return l, nil
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// closer releases the sinks a Logger was built with, once.
+type closer struct {
+	once  sync.Once
+	close func()
+	err   error
+	// closed is set once the sinks are released.
+	closed uint32
+}
+
+// Close flushes any buffered log entries, including those queued by the
+// cores of the sentrylog and otlplog packages, and then releases the sinks
+// the logger was built with by the constructors of this package, such as
+// buffered outputs and log files. The sinks are shared with the children of
+// the logger, which must not be used afterwards. Calling Close again is a
+// no-op, and a Logger not built by those constructors is only synced.
+func (l *Logger) Close() error {
+	if l.closer == nil {
+		return l.Sync()
+	}
+	l.closer.once.Do(func() {
+		l.closer.err = l.Sync()
+		l.closer.close()
+		atomic.StoreUint32(&l.closer.closed, 1)
+	})
+	return l.closer.err
+}
+
+// isClosed reports whether the sinks of the logger were released by Close.
+func (l *Logger) isClosed() bool {
+	return l.closer != nil && atomic.LoadUint32(&l.closer.closed) == 1
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger_Close(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l, err := NewProductionLogger(dir, true, zapcore.InfoLevel, true, WithBufferedWrites(4096, time.Hour))
+	require.NoError(t, err)
+	child := l.Named("child")
+	child.Infow("buffered until closed")
+
+	require.NoError(t, child.Close())
+	b, err := ioutil.ReadFile(filepath.Join(dir, "log.jsonl"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"msg":"buffered until closed"`)
+	assert.NoError(t, l.Close(), "the sinks are shared with the children and closed once")
+}
+
+func TestLogger_Close_NotBuiltByConstructors(t *testing.T) {
+	core, _ := observer.New(zapcore.InfoLevel)
+	l := NewLogger(zap.New(core))
+
+	assert.NoError(t, l.Close())
+	assert.NoError(t, l.Close())
+}
+
+func TestClose(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	defer ReplaceLogger(GetLogger())
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l, err := NewProductionLogger(dir, true, zapcore.InfoLevel, true, WithBufferedWrites(4096, time.Hour))
+	require.NoError(t, err)
+	ReplaceLogger(l)
+	Infow("buffered until closed")
+
+	require.NoError(t, Close())
+	b, err := ioutil.ReadFile(filepath.Join(dir, "log.jsonl"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"msg":"buffered until closed"`)
+	Infow("after closing")
+	assert.Error(t, GetLogger().Sync(), "the log file should be closed")
+}
@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// cloudWatchEncodingPrefix prefixes, in the Encoding of a zap.Config, the
+// CloudWatch namespace of the metrics of the cloudwatch encoding.
+const cloudWatchEncodingPrefix = "cloudwatch:"
+
+// defaultCloudWatchNamespace is the namespace of the metrics of the plain
+// "cloudwatch" encoding.
+const defaultCloudWatchNamespace = "chainlink"
+
+// WithCloudWatchEncoding encodes entries as JSON easily queried by
+// CloudWatch Logs Insights: the level under "level", the timestamp as
+// ISO8601 under "timestamp", and the fields at the top level. Metric fields
+// are additionally declared as CloudWatch Embedded Metric Format metrics of
+// the given namespace, so that CloudWatch extracts them from the entries.
+//
+// The pretty console can't read this format, so it is meant for loggers
+// with a JSON console. In a config file, it is the "cloudwatch:NAMESPACE"
+// encoding.
+func WithCloudWatchEncoding(namespace string) Option {
+	return func(config *zap.Config) {
+		config.Encoding = cloudWatchEncodingPrefix + namespace
+	}
+}
+
+// Metric returns a field holding a value with the given CloudWatch unit,
+// such as "Milliseconds" or "Count", and "None" if empty. With the
+// cloudwatch encoding, the field is written as a metric of the entry;
+// other encodings write the value and the unit as an object.
+func Metric(key string, value float64, unit string) zap.Field {
+	if unit == "" {
+		unit = "None"
+	}
+	return zap.Object(key, cloudWatchMetric{value: value, unit: unit})
+}
+
+type cloudWatchMetric struct {
+	value float64
+	unit  string
+}
+
+func (m cloudWatchMetric) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddFloat64("value", m.value)
+	enc.AddString("unit", m.unit)
+	return nil
+}
+
+// cloudWatchMetricDefinition declares a metric in the _aws metadata.
+type cloudWatchMetricDefinition struct {
+	name string
+	unit string
+}
+
+func (d cloudWatchMetricDefinition) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("Name", d.name)
+	enc.AddString("Unit", d.unit)
+	return nil
+}
+
+// cloudWatchEncoder is a JSON encoder writing the metric fields in the
+// Embedded Metric Format.
+type cloudWatchEncoder struct {
+	zapcore.Encoder
+	namespace string
+	// metrics are the metrics added with With.
+	metrics []cloudWatchMetricDefinition
+}
+
+func newCloudWatchEncoder(config zapcore.EncoderConfig, namespace string) zapcore.Encoder {
+	config.TimeKey = "timestamp"
+	config.EncodeTime = zapcore.ISO8601TimeEncoder
+	config.LevelKey = "level"
+	if namespace == "" {
+		namespace = defaultCloudWatchNamespace
+	}
+	return &cloudWatchEncoder{Encoder: zapcore.NewJSONEncoder(config), namespace: namespace}
+}
+
+func (e *cloudWatchEncoder) Clone() zapcore.Encoder {
+	return &cloudWatchEncoder{
+		Encoder:   e.Encoder.Clone(),
+		namespace: e.namespace,
+		metrics:   e.metrics[:len(e.metrics):len(e.metrics)],
+	}
+}
+
+// AddObject writes the metric fields added with With as metrics of the
+// entries.
+func (e *cloudWatchEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	if m, ok := obj.(cloudWatchMetric); ok {
+		e.Encoder.AddFloat64(key, m.value)
+		e.metrics = append(e.metrics, cloudWatchMetricDefinition{name: key, unit: m.unit})
+		return nil
+	}
+	return e.Encoder.AddObject(key, obj)
+}
+
+func (e *cloudWatchEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	metrics := e.metrics[:len(e.metrics):len(e.metrics)]
+	encoded := make([]zapcore.Field, 0, len(fields)+1)
+	for _, f := range fields {
+		if m, ok := f.Interface.(cloudWatchMetric); ok && f.Type == zapcore.ObjectMarshalerType {
+			encoded = append(encoded, zap.Float64(f.Key, m.value))
+			metrics = append(metrics, cloudWatchMetricDefinition{name: f.Key, unit: m.unit})
+			continue
+		}
+		encoded = append(encoded, f)
+	}
+	if len(metrics) > 0 {
+		encoded = append(encoded, zap.Object("_aws", cloudWatchMetadata{
+			timestamp: entry.Time.UnixNano() / 1e6,
+			namespace: e.namespace,
+			metrics:   metrics,
+		}))
+	}
+	return e.Encoder.EncodeEntry(entry, encoded)
+}
+
+// cloudWatchMetadata is the _aws metadata of the Embedded Metric Format,
+// declaring the metrics of an entry.
+type cloudWatchMetadata struct {
+	timestamp int64
+	namespace string
+	metrics   []cloudWatchMetricDefinition
+}
+
+func (md cloudWatchMetadata) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt64("Timestamp", md.timestamp)
+	return enc.AddArray("CloudWatchMetrics", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		return arr.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("Namespace", md.namespace)
+			// A single, empty, dimension set: the metrics are not broken
+			// down by dimension.
+			if err := enc.AddArray("Dimensions", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				return arr.AppendArray(zapcore.ArrayMarshalerFunc(func(zapcore.ArrayEncoder) error { return nil }))
+			})); err != nil {
+				return err
+			}
+			return enc.AddArray("Metrics", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				for _, d := range md.metrics {
+					if err := arr.AppendObject(d); err != nil {
+						return err
+					}
+				}
+				return nil
+			}))
+		}))
+	}))
+}
+
+// cloudWatchNamespace returns the metric namespace of a cloudwatch encoding.
+func cloudWatchNamespace(encoding string) (string, bool) {
+	if encoding == "cloudwatch" {
+		return "", true
+	}
+	if !strings.HasPrefix(encoding, cloudWatchEncodingPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(encoding, cloudWatchEncodingPrefix), true
+}
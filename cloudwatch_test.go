@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCloudWatchEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := newCloudWatchEncoder(zap.NewProductionEncoderConfig(), "my-service")
+	l := NewLogger(zap.New(zapcore.NewCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel)))
+
+	decode := func() map[string]interface{} {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		buf.Reset()
+		return entry
+	}
+
+	l.Infow("plain", "key", "value")
+	entry := decode()
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "value", entry["key"])
+	_, err := time.Parse("2006-01-02T15:04:05.000Z0700", entry["timestamp"].(string))
+	assert.NoError(t, err)
+	assert.NotContains(t, entry, "_aws")
+
+	l.With(Metric("attempts", 3, "Count")).Infow("measured", Metric("latency", 12.5, "Milliseconds"), "key", "value")
+	entry = decode()
+	assert.Equal(t, 3.0, entry["attempts"])
+	assert.Equal(t, 12.5, entry["latency"])
+	assert.Equal(t, "value", entry["key"])
+	metadata := entry["_aws"].(map[string]interface{})
+	assert.NotZero(t, metadata["Timestamp"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"Namespace":  "my-service",
+		"Dimensions": []interface{}{[]interface{}{}},
+		"Metrics": []interface{}{
+			map[string]interface{}{"Name": "attempts", "Unit": "Count"},
+			map[string]interface{}{"Name": "latency", "Unit": "Milliseconds"},
+		},
+	}}, metadata["CloudWatchMetrics"])
+}
+
+func TestMetric_OtherEncodings(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	NewLogger(zap.New(core)).Infow("measured", Metric("latency", 12.5, ""))
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, map[string]interface{}{
+		"latency": map[string]interface{}{"value": 12.5, "unit": "None"},
+	}, logs.All()[0].ContextMap())
+}
+
+func TestNewEncoder_CloudWatch(t *testing.T) {
+	config := zap.NewProductionConfig()
+	applyOptions(&config, []Option{WithCloudWatchEncoding("my-service")})
+	enc, err := newEncoder(config)
+	require.NoError(t, err)
+	assert.Equal(t, "my-service", enc.(*cloudWatchEncoder).namespace)
+
+	config.Encoding = "cloudwatch"
+	enc, err = newEncoder(config)
+	require.NoError(t, err)
+	assert.Equal(t, defaultCloudWatchNamespace, enc.(*cloudWatchEncoder).namespace)
+
+	config.Encoding = "xml"
+	_, err = newEncoder(config)
+	assert.EqualError(t, err, `unsupported encoding "xml"`)
+}
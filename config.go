@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// FileConfig is the configuration NewFromConfigFile builds a Logger from:
+// zap's Config, with the same keys, plus the extras of this package.
+type FileConfig struct {
+	zap.Config `yaml:",inline"`
+	// Rotation, if set, adds a log file rotated as configured to the
+	// outputs.
+	Rotation *RotationConfig `json:"rotation,omitempty" yaml:"rotation,omitempty"`
+	// RedactedKeys are registered with RegisterRedactedKeys.
+	RedactedKeys []string `json:"redactedKeys,omitempty" yaml:"redactedKeys,omitempty"`
+}
+
+// RotationConfig configures the rotating log file of a FileConfig.
+type RotationConfig struct {
+	// Dir is the directory of the log.jsonl file.
+	Dir           string `json:"dir" yaml:"dir"`
+	DiskLogConfig `yaml:",inline"`
+}
+
+// NewFromConfigFile returns a Logger built from the FileConfig in the file
+// at path, decoded as YAML if its extension is .yaml or .yml and as JSON
+// otherwise. Settings missing from the file keep the defaults of
+// zap.NewProductionConfig, and the level is shared with the package, see
+// SetLogLevel, which is only set to the level of the file once the logger
+// is built.
+func NewFromConfigFile(path string) (*Logger, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := FileConfig{Config: zap.NewProductionConfig()}
+	// Decoded apart from the level of the package, which is only set once
+	// the logger is built.
+	config.Level = zap.NewAtomicLevelAt(GetLogLevel())
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		err = dec.Decode(&config)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&config)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid logger config file %s", path)
+	}
+
+	if config.Rotation != nil {
+		destination, err := rotatingLogFileURI(config.Rotation.Dir, config.Rotation.DiskLogConfig)
+		if err != nil {
+			return nil, err
+		}
+		config.OutputPaths = append(config.OutputPaths, destination)
+	}
+	RegisterRedactedKeys(config.RedactedKeys...)
+
+	lvl := config.Level.Level()
+	config.Level = level
+	l, err := buildLogger(config.Config)
+	if err != nil {
+		return nil, err
+	}
+	SetLogLevel(lvl)
+	return l, nil
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewFromConfigFile(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	defer func() {
+		redactedKeysMu.Lock()
+		delete(redactedKeys, "apikey")
+		redactedKeysMu.Unlock()
+	}()
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	output := filepath.ToSlash(filepath.Join(dir, "output.jsonl"))
+	configs := map[string]string{
+		"config.json": `{
+			"level": "warn",
+			"encoding": "json",
+			"outputPaths": ["` + output + `"],
+			"sampling": {"initial": 10, "thereafter": 10},
+			"redactedKeys": ["apiKey"],
+			"rotation": {"dir": "` + filepath.ToSlash(dir) + `", "maxSizeMB": 1}
+		}`,
+		"config.yaml": `
+level: warn
+encoding: json
+outputPaths: ["` + output + `"]
+redactedKeys: [apiKey]
+rotation:
+  dir: ` + filepath.ToSlash(dir) + `
+  maxSizeMB: 1
+`,
+	}
+	for name, content := range configs {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+			defer os.Remove(filepath.Join(dir, "output.jsonl"))
+			defer os.Remove(filepath.Join(dir, "log.jsonl"))
+
+			l, err := NewFromConfigFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, zapcore.WarnLevel, GetLogLevel())
+			l.Info("filtered")
+			l.Warnw("from file", "apiKey", "secret")
+			// Closed, for the rotating file not to be shared with the
+			// logger of the next config.
+			_ = l.Close()
+
+			for _, file := range []string{"output.jsonl", "log.jsonl"} {
+				b, err := ioutil.ReadFile(filepath.Join(dir, file))
+				require.NoError(t, err)
+				assert.Contains(t, string(b), `"msg":"from file","apiKey":"[REDACTED]"`)
+				assert.NotContains(t, string(b), "filtered")
+			}
+		})
+	}
+}
+
+func TestNewFromConfigFile_Invalid(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	SetLogLevel(zapcore.InfoLevel)
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = NewFromConfigFile(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"levle": "warn"}`), 0600))
+	_, err = NewFromConfigFile(path)
+	assert.EqualError(t, err, "invalid logger config file "+path+`: json: unknown field "levle"`)
+
+	path = filepath.Join(dir, "config.yml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("level: loud\n"), 0600))
+	_, err = NewFromConfigFile(path)
+	assert.Error(t, err)
+
+	// Neither decoded nor built, the level doesn't change that of the package.
+	require.NoError(t, ioutil.WriteFile(path, []byte("level: debug\nlevle: warn\n"), 0600))
+	_, err = NewFromConfigFile(path)
+	assert.Error(t, err)
+	missing := filepath.ToSlash(filepath.Join(dir, "missing", "output.jsonl"))
+	require.NoError(t, ioutil.WriteFile(path, []byte("level: debug\noutputPaths: [\""+missing+"\"]\n"), 0600))
+	_, err = NewFromConfigFile(path)
+	assert.Error(t, err)
+	assert.Equal(t, zapcore.InfoLevel, GetLogLevel())
+}
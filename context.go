@@ -0,0 +1,23 @@
+package logger
+
+import "context"
+
+// loggerContextKey is the key of the Logger stored by ContextWithLogger.
+type loggerContextKey struct{}
+
+// ContextWithLogger returns a copy of ctx storing l, for FromContext to
+// retrieve it down the call chain of a request instead of threading it
+// through every call.
+func ContextWithLogger(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by ContextWithLogger, or the
+// logger of GetLogger if there is none. It pairs with WithContext, as in
+// FromContext(ctx).WithContext(ctx), to log with the trace of the request.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerContextKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return GetLogger()
+}
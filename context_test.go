@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext(t *testing.T) {
+	assert.Same(t, GetLogger(), FromContext(context.Background()))
+	assert.Same(t, GetLogger(), FromContext(ContextWithLogger(context.Background(), nil)))
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := NewLogger(zap.New(core)).With("request", 1)
+	ctx := ContextWithLogger(context.Background(), l)
+	assert.Same(t, l, FromContext(ctx))
+
+	FromContext(ctx).Info("scoped")
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, map[string]interface{}{"request": int64(1)}, logs.All()[0].ContextMap())
+}
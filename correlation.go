@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"crypto/rand"
+	"fmt"
+	mathrand "math/rand"
+)
+
+// CorrelationId is the field the IDs of WithNewCorrelationID are logged
+// under.
+const CorrelationId = "correlation_id"
+
+// WithNewCorrelationID returns a child logger that adds a new random UUID
+// to every subsequent entry under the correlation_id field, along with the
+// UUID, for it to be echoed in responses. It correlates the entries of the
+// requests that are not traced, for which WithSpan has nothing to add.
+func (l *Logger) WithNewCorrelationID() (*Logger, string) {
+	id := newCorrelationID()
+	return l.With(CorrelationId, id), id
+}
+
+// newCorrelationID returns a random, version 4, UUID.
+func newCorrelationID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// Still unique enough to correlate entries.
+		mathrand.Read(b[:])
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
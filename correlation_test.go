@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger_WithNewCorrelationID(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := NewLogger(zap.New(core))
+
+	child, id := l.WithNewCorrelationID()
+	other, otherID := l.WithNewCorrelationID()
+	child.Info("correlated")
+	other.Info("other request")
+	l.Info("uncorrelated")
+
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+	assert.NotEqual(t, id, otherID)
+	entries := logs.All()
+	require.Len(t, entries, 3)
+	assert.Equal(t, map[string]interface{}{CorrelationId: id}, entries[0].ContextMap())
+	assert.Equal(t, map[string]interface{}{CorrelationId: otherID}, entries[1].ContextMap())
+	assert.Empty(t, entries[2].ContextMap())
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"encoding/binary"
+	"strconv"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The keys Datadog correlates the entries with the APM traces by.
+const (
+	datadogTraceIDKey = "dd.trace_id"
+	datadogSpanIDKey  = "dd.span_id"
+)
+
+// datadogCorrelation is set, to 1, by EnableDatadogCorrelation.
+var datadogCorrelation int32
+
+// EnableDatadogCorrelation makes WithSpan and WithContext also add the
+// dd.trace_id and dd.span_id fields Datadog correlates entries with APM
+// traces by: the lower 64 bits of the trace ID, and the span ID, as decimal
+// numbers. It only affects the loggers derived afterwards.
+func EnableDatadogCorrelation() {
+	atomic.StoreInt32(&datadogCorrelation, 1)
+}
+
+// spanKeysAndValues returns the key value pairs identifying the span of sc.
+func spanKeysAndValues(sc trace.SpanContext) []interface{} {
+	traceID, spanID := sc.TraceID(), sc.SpanID()
+	kv := []interface{}{TraceId, traceID.String(), SpanId, spanID.String()}
+	if atomic.LoadInt32(&datadogCorrelation) == 1 {
+		kv = append(kv,
+			datadogTraceIDKey, strconv.FormatUint(binary.BigEndian.Uint64(traceID[8:]), 10),
+			datadogSpanIDKey, strconv.FormatUint(binary.BigEndian.Uint64(spanID[:]), 10),
+		)
+	}
+	return kv
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestEnableDatadogCorrelation(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewLogger(zap.New(core))
+	span := trace.SpanFromContext(testSpanContext())
+
+	l.WithSpan(span).Infow("without correlation")
+	EnableDatadogCorrelation()
+	defer atomic.StoreInt32(&datadogCorrelation, 0)
+	l.WithSpan(span).Infow("with span")
+	l.WithContext(testSpanContext()).Infow("with context")
+
+	entries := logs.All()
+	require.Len(t, entries, 3)
+	assert.NotContains(t, entries[0].ContextMap(), datadogTraceIDKey)
+	for _, entry := range entries[1:] {
+		assert.Equal(t, map[string]interface{}{
+			TraceId:           testTraceID.String(),
+			SpanId:            testSpanID.String(),
+			datadogTraceIDKey: "651345242494996240",
+			datadogSpanIDKey:  "72623859790382856",
+		}, entry.ContextMap())
+	}
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewDedupLogger returns a child of l that collapses entries with the same
+// level and message, regardless of their fields, logged within window of
+// the first one. The first entry is written right away, and when the window
+// closes, or on Sync, the number of entries dropped since is written as a
+// copy of it with a repeated field.
+//
+// Like NewSampledLogger, the log_lines_total counter counts every entry,
+// while log_bytes_total only counts the entries actually written.
+func NewDedupLogger(l *Logger, window time.Duration) *Logger {
+	state := &dedupState{window: window, seen: map[dedupKey]*dedupEntry{}}
+	zl := l.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &dedupCore{Core: core, state: state}
+	}))
+	return NewLogger(zl)
+}
+
+type dedupKey struct {
+	level zapcore.Level
+	msg   string
+}
+
+// dedupEntry is the first entry with a key in the current window, with the
+// core it was written to and the number of entries dropped since.
+type dedupEntry struct {
+	core     zapcore.Core
+	entry    zapcore.Entry
+	repeated int
+	timer    *time.Timer
+}
+
+type dedupState struct {
+	window time.Duration
+	mu     sync.Mutex
+	seen   map[dedupKey]*dedupEntry
+}
+
+// flush ends the window of key, writing how many entries were dropped in it.
+func (s *dedupState) flush(key dedupKey) {
+	s.mu.Lock()
+	e, ok := s.seen[key]
+	delete(s.seen, key)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	e.timer.Stop()
+	if e.repeated == 0 {
+		return
+	}
+	entry := e.entry
+	entry.Time = time.Now()
+	if ce := e.core.Check(entry, nil); ce != nil {
+		ce.Write(zap.Int("repeated", e.repeated))
+	}
+}
+
+func (s *dedupState) flushAll() {
+	s.mu.Lock()
+	keys := make([]dedupKey, 0, len(s.seen))
+	for key := range s.seen {
+		keys = append(keys, key)
+	}
+	s.mu.Unlock()
+	for _, key := range keys {
+		s.flush(key)
+	}
+}
+
+type dedupCore struct {
+	zapcore.Core
+	state *dedupState
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields), state: c.state}
+}
+
+func (c *dedupCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return ce
+	}
+	key := dedupKey{level: entry.Level, msg: entry.Message}
+	c.state.mu.Lock()
+	if e, ok := c.state.seen[key]; ok {
+		e.repeated++
+		c.state.mu.Unlock()
+		return ce
+	}
+	c.state.seen[key] = &dedupEntry{
+		core:  c.Core,
+		entry: entry,
+		timer: time.AfterFunc(c.state.window, func() { c.state.flush(key) }),
+	}
+	c.state.mu.Unlock()
+	return c.Core.Check(entry, ce)
+}
+
+func (c *dedupCore) Sync() error {
+	c.state.flushAll()
+	return c.Core.Sync()
+}
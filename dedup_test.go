@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewDedupLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewDedupLogger(NewLogger(zap.New(core)), time.Hour)
+
+	for i := 0; i < 5; i++ {
+		l.Warnw("flood", "i", i)
+	}
+	l.Errorw("flood")
+	l.Warnw("other")
+
+	require.Equal(t, 3, logs.Len())
+	assert.Equal(t, map[string]interface{}{"i": int64(0)}, logs.All()[0].ContextMap())
+
+	require.NoError(t, l.Sync())
+	repeated := logs.FilterField(zap.Int("repeated", 4)).All()
+	require.Len(t, repeated, 1)
+	assert.Equal(t, "flood", repeated[0].Message)
+	assert.Equal(t, zapcore.WarnLevel, repeated[0].Level)
+	assert.Equal(t, 4, logs.Len(), "entries logged once are not repeated")
+
+	l.Warnw("flood")
+	assert.Equal(t, 5, logs.Len(), "Sync starts a new window")
+}
+
+func TestNewDedupLogger_WindowCloses(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewDedupLogger(NewLogger(zap.New(core)), 10*time.Millisecond)
+
+	l.Info("flood")
+	l.Info("flood")
+	require.Eventually(t, func() bool {
+		return logs.FilterField(zap.Int("repeated", 1)).Len() == 1
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// NewFromEnv returns a Logger configured by the environment variables:
+//
+//	LOG_LEVEL     the minimum level, info by default
+//	LOG_FORMAT    json or pretty, pretty by default unless LOG_OUTPUT is a
+//	              file path
+//	LOG_OUTPUT    stderr, stdout or a file path, stderr by default
+//	LOG_TO_DISK   whether to also write JSON to log.jsonl in LOG_FILE_DIR
+//	LOG_FILE_DIR  the directory of log.jsonl, the working directory by
+//	              default
+//
+// Invalid values are reported as errors naming the variable. The level is
+// shared with the package, see SetLogLevel.
+func NewFromEnv() (*Logger, error) {
+	lvl, err := envLogLevel()
+	if err != nil {
+		return nil, err
+	}
+
+	output := os.Getenv("LOG_OUTPUT")
+	if output == "" {
+		output = "stderr"
+	}
+	console := output == "stderr" || output == "stdout"
+
+	var jsonConsole bool
+	switch format := os.Getenv("LOG_FORMAT"); format {
+	case "":
+		jsonConsole = !console
+	case "json":
+		jsonConsole = true
+	case "pretty":
+		if !console {
+			return nil, errors.Errorf("invalid LOG_FORMAT %q: pretty output requires LOG_OUTPUT stderr or stdout, got %q", format, output)
+		}
+	default:
+		return nil, errors.Errorf("invalid LOG_FORMAT %q: must be json or pretty", format)
+	}
+
+	var toDisk bool
+	if v := os.Getenv("LOG_TO_DISK"); v != "" {
+		toDisk, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.Errorf("invalid LOG_TO_DISK %q: must be a boolean", v)
+		}
+	}
+
+	config := productionConfig(jsonConsole, lvl)
+	switch {
+	case !jsonConsole && output == "stdout":
+		config.OutputPaths = []string{"pretty://stdout"}
+	case jsonConsole:
+		config.OutputPaths = []string{output}
+	}
+	if toDisk {
+		destination := logFileURI(os.Getenv("LOG_FILE_DIR"))
+		config.OutputPaths = append(config.OutputPaths, destination)
+		config.ErrorOutputPaths = append(config.ErrorOutputPaths, destination)
+	}
+	return buildLogger(config)
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	vars := []string{"LOG_LEVEL", "LOG_FORMAT", "LOG_OUTPUT", "LOG_TO_DISK", "LOG_FILE_DIR"}
+	for _, key := range vars {
+		original, set := os.LookupEnv(key)
+		defer func(key string) {
+			if set {
+				os.Setenv(key, original)
+			} else {
+				os.Unsetenv(key)
+			}
+		}(key)
+	}
+	setEnv := func(env map[string]string) {
+		for _, key := range vars {
+			os.Setenv(key, env[key])
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("file output", func(t *testing.T) {
+		output := filepath.Join(dir, "output.jsonl")
+		setEnv(map[string]string{
+			"LOG_LEVEL":    "warn",
+			"LOG_OUTPUT":   output,
+			"LOG_TO_DISK":  "true",
+			"LOG_FILE_DIR": dir,
+		})
+		l, err := NewFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, zapcore.WarnLevel, GetLogLevel())
+		l.Info("filtered")
+		l.Warnw("from env", "key", "value")
+		_ = l.Sync()
+
+		for _, name := range []string{"output.jsonl", "log.jsonl"} {
+			b, err := ioutil.ReadFile(filepath.Join(dir, name))
+			require.NoError(t, err)
+			assert.Contains(t, string(b), `"msg":"from env","key":"value"`, "JSON is the default format of files")
+			assert.NotContains(t, string(b), "filtered")
+		}
+	})
+
+	t.Run("trace", func(t *testing.T) {
+		output := filepath.Join(dir, "trace.jsonl")
+		setEnv(map[string]string{"LOG_LEVEL": "TRACE", "LOG_OUTPUT": output})
+		l, err := NewFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, TraceLevel, GetLogLevel())
+		l.Tracew("traced", "key", "value")
+		_ = l.Sync()
+
+		b, err := ioutil.ReadFile(output)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"level":"trace"`)
+		assert.Contains(t, string(b), `"msg":"traced","key":"value"`)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		setEnv(nil)
+		_, err := NewFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, zapcore.InfoLevel, GetLogLevel())
+	})
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"level", map[string]string{"LOG_LEVEL": "loud"}, `invalid LOG_LEVEL: unrecognized level: "loud"`},
+		{"format", map[string]string{"LOG_FORMAT": "xml"}, `invalid LOG_FORMAT "xml": must be json or pretty`},
+		{"pretty file", map[string]string{"LOG_FORMAT": "pretty", "LOG_OUTPUT": "out.log"},
+			`invalid LOG_FORMAT "pretty": pretty output requires LOG_OUTPUT stderr or stdout, got "out.log"`},
+		{"to disk", map[string]string{"LOG_TO_DISK": "maybe"}, `invalid LOG_TO_DISK "maybe": must be a boolean`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEnv(tt.env)
+			_, err := NewFromEnv()
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
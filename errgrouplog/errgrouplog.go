@@ -0,0 +1,27 @@
+// Package errgrouplog logs the failures of the goroutines of an
+// errgroup.Group through a logger.Logger, with the purpose of the goroutine.
+//
+// It is a separate module, as golang.org/x/sync/errgroup requires a more
+// recent Go than the logger package.
+package errgrouplog
+
+import (
+	"github.com/smartcontractkit/logger"
+	"golang.org/x/sync/errgroup"
+)
+
+// Goroutine is the field the name of the failed goroutine is logged under.
+const Goroutine = "goroutine"
+
+// GoLogged runs f in a new goroutine of g, as g.Go does, and logs the error
+// it returns, if any, at the error level with the given name, describing
+// the purpose of the goroutine, before g returns it from Wait.
+func GoLogged(g *errgroup.Group, name string, f func() error, l *logger.Logger) {
+	g.Go(func() error {
+		err := f()
+		if err == nil {
+			return nil
+		}
+		return l.With(Goroutine, name).LogError(err, "goroutine failed")
+	})
+}
@@ -0,0 +1,34 @@
+package errgrouplog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartcontractkit/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestGoLogged(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := logger.NewLogger(zap.New(core))
+
+	fetchErr := errors.New("connection refused")
+	var g errgroup.Group
+	GoLogged(&g, "fetch prices", func() error { return fetchErr }, l)
+	GoLogged(&g, "watch heads", func() error { return nil }, l)
+	assert.Equal(t, fetchErr, g.Wait())
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+	assert.Equal(t, "goroutine failed", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{
+		Goroutine: "fetch prices",
+		"error":   "connection refused",
+	}, entries[0].ContextMap())
+}
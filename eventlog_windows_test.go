@@ -0,0 +1,33 @@
+// +build windows
+
+package logger
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+func TestNewEventLogSink_Invalid(t *testing.T) {
+	u, err := url.Parse("windows://")
+	require.NoError(t, err)
+	_, err = newEventLogSink(u)
+	assert.EqualError(t, err, "missing event log source in windows:// output path")
+
+	u, err = url.Parse("windows://logger?eventID=big")
+	require.NoError(t, err)
+	_, err = newEventLogSink(u)
+	assert.EqualError(t, err, `invalid eventID for event log: strconv.ParseUint: parsing "big": invalid syntax`)
+}
+
+func TestEventType(t *testing.T) {
+	assert.Equal(t, uint32(eventlog.Error), eventType("error"))
+	assert.Equal(t, uint32(eventlog.Error), eventType("FATAL"))
+	assert.Equal(t, uint32(eventlog.Warning), eventType("WARN"))
+	assert.Equal(t, uint32(eventlog.Warning), eventType("warning"))
+	assert.Equal(t, uint32(eventlog.Info), eventType("Info"))
+	assert.Equal(t, uint32(eventlog.Info), eventType("trace"))
+}
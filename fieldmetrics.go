@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+// labelNamePattern matches the valid Prometheus label names.
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+var (
+	// trackedFieldsMu guards trackedFields, which is replaced, rather than
+	// updated, when a field is tracked.
+	trackedFieldsMu sync.RWMutex
+	trackedFields   []*trackedField
+)
+
+// trackedField counts the entries with a field, by value.
+type trackedField struct {
+	key     string
+	counter *prometheus.CounterVec
+	values  *boundedLabelValues
+}
+
+// TrackFieldAsMetric counts the entries written by the loggers built by
+// this package with the field of the given key, such as job_type, by its
+// value, in the log_lines_by_<key>_total counter, labeled with the key. At
+// most maxValues distinct values are counted under their own label, to
+// bound the number of series: the entries with the other values are
+// counted under the _other label value.
+//
+// The field is read from the fields of the entry and those added with
+// With, as text. As with log_bytes_total, only the entries actually
+// written are counted, after sampling. The key must be a valid Prometheus
+// label name; tracking a field again has no effect.
+func TrackFieldAsMetric(key string, maxValues int) error {
+	if !labelNamePattern.MatchString(key) {
+		return errors.Errorf("invalid field name %q: not a valid label name", key)
+	}
+	if maxValues <= 0 {
+		return errors.Errorf("invalid maxValues %d for field %q: must be positive", maxValues, key)
+	}
+
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	trackedFieldsMu.Lock()
+	defer trackedFieldsMu.Unlock()
+
+	for _, t := range trackedFields {
+		if t.key == key {
+			return nil
+		}
+	}
+	t := &trackedField{
+		key:     key,
+		counter: newFieldCounter(metricsNamespace, metricsSubsystem, key),
+		values:  newBoundedLabelValues(maxValues),
+	}
+	if metricsRegisterer != nil {
+		if err := metricsRegisterer.Register(t.counter); err != nil {
+			return err
+		}
+	}
+	trackedFields = append(trackedFields[:len(trackedFields):len(trackedFields)], t)
+	return nil
+}
+
+// newFieldCounter returns the counter of the entries with the field of the
+// given key, by value.
+func newFieldCounter(namespace, subsystem, key string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "log_lines_by_" + key + "_total",
+	}, []string{key})
+}
+
+// getTrackedFields returns the tracked fields.
+func getTrackedFields() []*trackedField {
+	trackedFieldsMu.RLock()
+	defer trackedFieldsMu.RUnlock()
+	return trackedFields
+}
+
+// setTrackedFields replaces the tracked fields with fields.
+func setTrackedFields(fields []*trackedField) {
+	trackedFieldsMu.Lock()
+	defer trackedFieldsMu.Unlock()
+	trackedFields = fields
+}
+
+// count counts an entry with the given value of the field.
+func (t *trackedField) count(value string) {
+	t.counter.WithLabelValues(t.values.label(value)).Inc()
+}
+
+// fieldMetricCore counts the entries written by the wrapped core in the
+// counters of the tracked fields.
+type fieldMetricCore struct {
+	zapcore.Core
+	// context are the fields added with With.
+	context []zapcore.Field
+}
+
+func newFieldMetricCore(core zapcore.Core) zapcore.Core {
+	return &fieldMetricCore{Core: core}
+}
+
+func (c *fieldMetricCore) With(fields []zapcore.Field) zapcore.Core {
+	context := append(c.context[:len(c.context):len(c.context)], fields...)
+	return &fieldMetricCore{Core: c.Core.With(fields), context: context}
+}
+
+func (c *fieldMetricCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if len(getTrackedFields()) == 0 {
+		return c.Core.Check(entry, ce)
+	}
+	// Only count the entries the wrapped core, which may be sampling, writes.
+	if downstream, ok := checkDownstream(c.Core, entry, ce); ok {
+		return downstream.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write only counts the entry, the wrapped core was added to the checked
+// entry by Check and writes it itself.
+func (c *fieldMetricCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	for _, t := range getTrackedFields() {
+		value, ok := fieldText(fields, t.key)
+		if !ok {
+			value, ok = fieldText(c.context, t.key)
+		}
+		if ok {
+			t.count(value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTrackFieldAsMetric(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, SetMetricsRegisterer(registry))
+	defer func() {
+		trackedFields = nil
+		require.NoError(t, SetMetricsRegisterer(prometheus.DefaultRegisterer))
+	}()
+
+	assert.EqualError(t, TrackFieldAsMetric("job-type", 2), `invalid field name "job-type": not a valid label name`)
+	assert.EqualError(t, TrackFieldAsMetric("job_type", 0), `invalid maxValues 0 for field "job_type": must be positive`)
+	require.NoError(t, TrackFieldAsMetric("job_type", 2))
+	require.NoError(t, TrackFieldAsMetric("job_type", 5), "tracking a field again has no effect")
+
+	l := NewWriterLogger(ioutil.Discard, zapcore.InfoLevel, true)
+	l.Infow("ran", "job_type", "cron")
+	l.With("job_type", "cron").Warn("ran")
+	l.With("job_type", "cron").Infow("overridden", "job_type", "webhook")
+	l.Infow("ran", "job_type", "keeper")
+	l.Infow("ran", "job_type", "flux")
+	l.Debugw("disabled", "job_type", "cron")
+	l.Info("untracked")
+
+	counter := getTrackedFields()[0].counter
+	assert.Equal(t, 2.0, testutil.ToFloat64(counter.WithLabelValues("cron")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(counter.WithLabelValues("webhook")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(counter.WithLabelValues(otherLabelValue)), "values past the maximum are counted together")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var names []string
+	for _, f := range families {
+		names = append(names, f.GetName())
+	}
+	assert.Contains(t, names, "log_lines_by_job_type_total")
+
+	require.NoError(t, ConfigureMetrics("chainlink", ""))
+	defer func() { require.NoError(t, ConfigureMetrics("", "")) }()
+	l.Infow("ran", "job_type", "cron")
+	families, err = registry.Gather()
+	require.NoError(t, err)
+	names = nil
+	for _, f := range families {
+		names = append(names, f.GetName())
+	}
+	assert.Contains(t, names, "chainlink_log_lines_by_job_type_total")
+	assert.NotContains(t, names, "log_lines_by_job_type_total")
+}
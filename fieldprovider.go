@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	fieldProvidersMu sync.RWMutex
+	fieldProviders   []func() []zap.Field
+)
+
+// RegisterFieldProvider registers f to be called for every entry logged by
+// the loggers built by this package, including the package logger and the
+// loggers built before the registration, to add the fields it returns to
+// the entry, such as the current block height. Unlike SetGlobalFields, the
+// fields are fresh for every entry.
+//
+// f is called as the entry is logged, on the goroutine logging it, so it
+// must be cheap and must not block. The fields of the providers come after
+// those added with With and before those of the entry itself, in the order
+// of registration. A provider that panics has its fields replaced by a
+// field_provider_panic field holding the value it panicked with; the entry
+// is logged regardless.
+func RegisterFieldProvider(f func() []zap.Field) {
+	fieldProvidersMu.Lock()
+	defer fieldProvidersMu.Unlock()
+	fieldProviders = append(fieldProviders, f)
+}
+
+// providedFields returns the fields of the registered providers.
+func providedFields() []zap.Field {
+	fieldProvidersMu.RLock()
+	providers := fieldProviders
+	fieldProvidersMu.RUnlock()
+
+	var fields []zap.Field
+	for _, provider := range providers {
+		fields = append(fields, callFieldProvider(provider)...)
+	}
+	return fields
+}
+
+func callFieldProvider(provider func() []zap.Field) (fields []zap.Field) {
+	defer func() {
+		if r := recover(); r != nil {
+			fields = []zap.Field{zap.Any("field_provider_panic", r)}
+		}
+	}()
+	return provider()
+}
+
+// fieldProviderCore adds the fields of the registered providers to the
+// entries of the core it wraps.
+type fieldProviderCore struct {
+	zapcore.Core
+}
+
+func newFieldProviderCore(core zapcore.Core) zapcore.Core {
+	return &fieldProviderCore{Core: core}
+}
+
+func (c *fieldProviderCore) With(fields []zapcore.Field) zapcore.Core {
+	return &fieldProviderCore{Core: c.Core.With(fields)}
+}
+
+func (c *fieldProviderCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return ce
+	}
+	// The fields are added to the wrapped core, rather than passed along
+	// when the entry is written, so that its cores still check and write
+	// the entry themselves.
+	if fields := providedFields(); len(fields) > 0 {
+		return c.Core.With(fields).Check(entry, ce)
+	}
+	return c.Core.Check(entry, ce)
+}
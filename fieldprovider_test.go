@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRegisterFieldProvider(t *testing.T) {
+	defer func() { fieldProviders = nil }()
+
+	var buf bytes.Buffer
+	l := NewWriterLogger(&buf, zapcore.InfoLevel, true)
+
+	height := 0
+	RegisterFieldProvider(func() []zap.Field {
+		height++
+		return []zap.Field{zap.Int("height", height)}
+	})
+	RegisterFieldProvider(func() []zap.Field {
+		panic("broken provider")
+	})
+
+	l.Debug("disabled")
+	l.With("job", "poller").Infow("first", "key", "value")
+	l.Info("second")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Regexp(t, `"job":"poller","height":1,"field_provider_panic":"broken provider","key":"value"}$`, lines[0])
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, 2.0, entry["height"], "providers are called for every entry")
+	assert.Equal(t, 2, height, "providers are not called for disabled entries")
+}
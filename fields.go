@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Duration returns a field holding d as a number of milliseconds, such as
+// 1500.25 for 1.50025s, so that durations compare and aggregate the same
+// way in every service.
+func Duration(key string, d time.Duration) zap.Field {
+	return zap.Float64(key, float64(d)/float64(time.Millisecond))
+}
+
+// byteUnits are the units of Bytes, each 1024 times the previous one.
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// Bytes returns a field holding the size n, in bytes, with the largest
+// binary unit it has at least one of, such as "512 B" or "1.5 MiB".
+func Bytes(key string, n int64) zap.Field {
+	return zap.String(key, formatBytes(n))
+}
+
+func formatBytes(n int64) string {
+	if n > -1024 && n < 1024 {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	size, unit := float64(n), 0
+	for (size >= 1024 || size <= -1024) && unit < len(byteUnits)-1 {
+		size /= 1024
+		unit++
+	}
+	// One decimal is enough to tell sizes of a unit apart.
+	return strconv.FormatFloat(math.Round(size*10)/10, 'f', -1, 64) + " " + byteUnits[unit]
+}
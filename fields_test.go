@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDurationAndBytes(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	NewLogger(zap.New(core)).Infow("measured",
+		Duration("elapsed", 1500250*time.Microsecond),
+		Bytes("size", 3<<20+512<<10),
+		"key", "value",
+	)
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, map[string]interface{}{
+		"elapsed": 1500.25,
+		"size":    "3.5 MiB",
+		"key":     "value",
+	}, logs.All()[0].ContextMap())
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:          "0 B",
+		512:        "512 B",
+		1023:       "1023 B",
+		1024:       "1 KiB",
+		1536:       "1.5 KiB",
+		-2048:      "-2 KiB",
+		5 << 30:    "5 GiB",
+		1<<62 + 1:  "4 EiB",
+		1<<40 + 99: "1 TiB",
+	} {
+		assert.Equal(t, want, formatBytes(n), n)
+	}
+}
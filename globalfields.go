@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// globalFieldKeys are the keys of the global fields in the order they
+	// were first set, and globalFields their key value pairs, or strongly
+	// typed field, by key. Both are guarded by loggerMu.
+	globalFieldKeys []string
+	globalFields    = map[string][]interface{}{}
+)
+
+// SetGlobalFields adds the given key value pairs to every subsequent entry
+// logged through the package-level functions, such as the name and version
+// of the service. Later calls are merged with the earlier ones, replacing
+// the values of the keys they set again. The fields outlive SetLogger, and
+// are not part of GetLogger, which can so be wrapped and set again without
+// duplicating them: use GetLogger().With for a logger of their own.
+func SetGlobalFields(keysAndValues ...interface{}) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+
+	for i := 0; i < len(keysAndValues); i++ {
+		var key string
+		var field []interface{}
+		switch k := keysAndValues[i].(type) {
+		case zap.Field:
+			// Strongly typed fields stand on their own, without a value.
+			key, field = k.Key, keysAndValues[i:i+1]
+		default:
+			if i+1 == len(keysAndValues) {
+				// Left for the logger to report, like any key without a
+				// value.
+				key, field = fmt.Sprint(k), keysAndValues[i:]
+			} else {
+				key, field = fmt.Sprint(k), keysAndValues[i:i+2]
+			}
+			i++
+		}
+		if _, ok := globalFields[key]; !ok {
+			globalFieldKeys = append(globalFieldKeys, key)
+		}
+		globalFields[key] = field
+	}
+	if logger != nil {
+		pkgLogger = newPackageLogger(logger)
+	}
+}
+
+// ClearGlobalFields removes the fields set by SetGlobalFields.
+func ClearGlobalFields() {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	globalFieldKeys, globalFields = nil, map[string][]interface{}{}
+	if logger != nil {
+		pkgLogger = newPackageLogger(logger)
+	}
+}
+
+// newPackageLogger returns the logger the package-level functions log
+// through when l is the package logger: l with an extra caller skip and
+// the global fields. The caller must hold loggerMu.
+func newPackageLogger(l *Logger) *Logger {
+	pl := NewLogger(l.Desugar().WithOptions(zap.AddCallerSkip(1)))
+	if len(globalFieldKeys) == 0 {
+		return pl
+	}
+	var kv []interface{}
+	for _, key := range globalFieldKeys {
+		kv = append(kv, globalFields[key]...)
+	}
+	return pl.With(kv...)
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSetGlobalFields(t *testing.T) {
+	defer ClearGlobalFields()
+	core, logs := observer.New(zapcore.DebugLevel)
+	SetLogger(zap.New(core))
+
+	SetGlobalFields("service", "node", "version", "1.0.0")
+	Infow("first", "key", "value")
+	SetGlobalFields(zap.String("instance_id", "i-1"), "version", "1.0.1")
+	Info("merged")
+	GetLogger().Info("instance")
+
+	// The fields outlive SetLogger.
+	core, later := observer.New(zapcore.DebugLevel)
+	SetLogger(zap.New(core))
+	Warn("replaced logger")
+
+	ClearGlobalFields()
+	Info("cleared")
+
+	entries := logs.All()
+	require.Len(t, entries, 3)
+	assert.Equal(t, map[string]interface{}{"service": "node", "version": "1.0.0", "key": "value"}, entries[0].ContextMap())
+	assert.Equal(t, map[string]interface{}{"service": "node", "version": "1.0.1", "instance_id": "i-1"}, entries[1].ContextMap())
+	assert.Empty(t, entries[2].ContextMap(), "GetLogger should not carry the global fields")
+
+	entries = later.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, map[string]interface{}{"service": "node", "version": "1.0.1", "instance_id": "i-1"}, entries[0].ContextMap())
+	assert.Empty(t, entries[1].ContextMap())
+}
+
+func TestSetGlobalFields_Order(t *testing.T) {
+	defer ClearGlobalFields()
+	core, logs := observer.New(zapcore.DebugLevel)
+	SetLogger(zap.New(core))
+
+	SetGlobalFields("b", 1, "a", 2)
+	SetGlobalFields("b", 3)
+	Info("ordered")
+
+	require.Equal(t, 1, logs.Len())
+	context := logs.All()[0].Context
+	require.Len(t, context, 2)
+	assert.Equal(t, "b", context[0].Key)
+	assert.Equal(t, int64(3), context[0].Integer)
+	assert.Equal(t, "a", context[1].Key)
+}
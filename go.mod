@@ -4,9 +4,19 @@ go 1.14
 
 require (
 	github.com/fatih/color v1.9.0
+	github.com/getsentry/sentry-go v0.11.0
+	github.com/mattn/go-isatty v0.0.11
 	github.com/pkg/errors v0.8.1
 	github.com/prometheus/client_golang v1.5.1
-	github.com/stretchr/testify v1.4.0
+	github.com/segmentio/kafka-go v0.4.17
+	github.com/stretchr/testify v1.7.0
 	github.com/tidwall/gjson v1.6.0
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
+	go.uber.org/multierr v1.5.0
 	go.uber.org/zap v1.16.0
+	golang.org/x/sys v0.0.0-20200323222414-85ca7c5b95cd
+	google.golang.org/grpc v1.40.0
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,51 @@
+// Package grpclogging provides gRPC server interceptors logging every call
+// through a logger.Logger.
+package grpclogging
+
+import (
+	"context"
+	"time"
+
+	"github.com/smartcontractkit/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns an interceptor logging the method, duration
+// and status code of every unary call, at the info level when the call
+// succeeds and at the error level otherwise. The trace ID of the span in the
+// call's context, if any, is logged along.
+func UnaryServerInterceptor(l *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(l.WithContext(ctx), info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns the streaming equivalent of
+// UnaryServerInterceptor, logging once the stream is finished.
+func StreamServerInterceptor(l *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(l.WithContext(ss.Context()), info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(l *logger.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	keysAndValues := []interface{}{
+		"method", method,
+		"duration", time.Since(start),
+		"code", code.String(),
+	}
+	if code == codes.OK {
+		l.Infow("finished gRPC call", keysAndValues...)
+		return
+	}
+	l.Errorw("finished gRPC call", append(keysAndValues, "error", err)...)
+}
@@ -0,0 +1,68 @@
+package grpclogging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smartcontractkit/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	l, logs := loggertest.NewTestLogger()
+	interceptor := UnaryServerInterceptor(l)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	traceID := trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{0x01}})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	resp, err := interceptor(ctx, "req", info, func(context.Context, interface{}) (interface{}, error) {
+		return "resp", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+
+	_, err = interceptor(context.Background(), "req", info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	})
+	require.Error(t, err)
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	assert.Equal(t, "/test.Service/Method", entries[0].ContextMap()["method"])
+	assert.Equal(t, "OK", entries[0].ContextMap()["code"])
+	assert.Equal(t, traceID.String(), entries[0].ContextMap()["TraceID"])
+	assert.Equal(t, zapcore.ErrorLevel, entries[1].Level)
+	assert.Equal(t, "NotFound", entries[1].ContextMap()["code"])
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	l, logs := loggertest.NewTestLogger()
+	interceptor := StreamServerInterceptor(l)
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+
+	err := interceptor(nil, testServerStream{}, info, func(interface{}, grpc.ServerStream) error {
+		return status.Error(codes.Internal, "broken")
+	})
+	require.Error(t, err)
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+	assert.Equal(t, "/test.Service/Stream", entries[0].ContextMap()["method"])
+	assert.Equal(t, "Internal", entries[0].ContextMap()["code"])
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+}
+
+func (testServerStream) Context() context.Context { return context.Background() }
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// fatalHooksTimeout bounds how long the fatal hooks may delay the exit.
+const fatalHooksTimeout = 5 * time.Second
+
+var (
+	fatalHooksMu sync.Mutex
+	fatalHooks   []func()
+
+	entryHooksMu sync.RWMutex
+	entryHooks   []func(zapcore.Entry) error
+)
+
+// RegisterHook registers f to run for every entry logged by the loggers
+// built by this package, including the package logger and the loggers
+// built before the registration. Hooks run once the entry is written, in
+// the order of registration, and before the fatal hooks. An error returned
+// by a hook is reported to the error output of the logger; it neither
+// stops the entry from being logged nor the other hooks from running.
+func RegisterHook(f func(zapcore.Entry) error) {
+	entryHooksMu.Lock()
+	defer entryHooksMu.Unlock()
+	entryHooks = append(entryHooks, f)
+}
+
+// hooksCore runs the entry and fatal hooks once the wrapped core has
+// written an entry, as zap.Hooks does, but can be told apart from the other
+// cores, for NewMultiLogger to run the hooks once rather than once per
+// wrapped logger.
+type hooksCore struct {
+	zapcore.Core
+}
+
+func newHooksCore(core zapcore.Core) zapcore.Core {
+	return &hooksCore{Core: core}
+}
+
+func (c *hooksCore) With(fields []zapcore.Field) zapcore.Core {
+	return &hooksCore{Core: c.Core.With(fields)}
+}
+
+func (c *hooksCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if downstream, ok := checkDownstream(c.Core, entry, ce); ok {
+		return downstream.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write only runs the hooks, the wrapped core was added to the checked
+// entry by Check and writes it itself.
+func (c *hooksCore) Write(entry zapcore.Entry, _ []zapcore.Field) error {
+	return multierr.Append(runEntryHooks(entry), runFatalHooks(entry))
+}
+
+// runEntryHooks is run by hooksCore once every core has written the entry.
+func runEntryHooks(entry zapcore.Entry) error {
+	entryHooksMu.RLock()
+	hooks := entryHooks
+	entryHooksMu.RUnlock()
+
+	var err error
+	for _, hook := range hooks {
+		err = multierr.Append(err, hook(entry))
+	}
+	return err
+}
+
+// RegisterFatalHook registers f to run when an entry is logged at the fatal
+// level by a logger built by this package, after the entry is written and
+// before the process exits. Hooks run in the reverse order of registration,
+// and the process exits regardless once they have run for 5 seconds.
+func RegisterFatalHook(f func()) {
+	fatalHooksMu.Lock()
+	defer fatalHooksMu.Unlock()
+	fatalHooks = append(fatalHooks, f)
+}
+
+// runFatalHooks is run by hooksCore once every core has written the entry.
+func runFatalHooks(entry zapcore.Entry) error {
+	if entry.Level != zapcore.FatalLevel {
+		return nil
+	}
+
+	fatalHooksMu.Lock()
+	hooks := append([]func(){}, fatalHooks...)
+	fatalHooksMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := len(hooks) - 1; i >= 0; i-- {
+			runFatalHook(hooks[i])
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(fatalHooksTimeout):
+	}
+	return nil
+}
+
+// runFatalHook runs f, recovering from a panic so the remaining hooks still
+// run.
+func runFatalHook(f func()) {
+	defer func() { _ = recover() }()
+	f()
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRegisterFatalHook(t *testing.T) {
+	defer func() { fatalHooks = nil }()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	var calls []string
+	RegisterFatalHook(func() { calls = append(calls, "first") })
+	RegisterFatalHook(func() { panic("broken hook") })
+	RegisterFatalHook(func() {
+		calls = append(calls, "last")
+		assert.Equal(t, 1, logs.FilterMessage("fatal").Len(), "hooks run once the entry is written")
+	})
+
+	// Exit the goroutine rather than the test process on fatal.
+	l := NewLogger(zap.New(core, zap.Hooks(runFatalHooks), zap.OnFatal(zapcore.WriteThenGoexit)))
+	l.Error("not fatal")
+	assert.Empty(t, calls)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Fatal("fatal")
+	}()
+	<-done
+
+	assert.Equal(t, []string{"last", "first"}, calls)
+}
+
+func TestRegisterHook(t *testing.T) {
+	defer func() { entryHooks = nil }()
+
+	var calls []string
+	RegisterHook(func(entry zapcore.Entry) error {
+		calls = append(calls, "first "+entry.Message)
+		return errors.New("broken hook")
+	})
+	RegisterHook(func(entry zapcore.Entry) error {
+		calls = append(calls, "second "+entry.Message)
+		return nil
+	})
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	var errorOutput bytes.Buffer
+	l := NewLogger(zap.New(core, zap.Hooks(runEntryHooks), zap.ErrorOutput(zapcore.AddSync(&errorOutput))))
+	l.Debug("disabled")
+	l.Info("hooked")
+
+	assert.Equal(t, []string{"first hooked", "second hooked"}, calls)
+	assert.Equal(t, 1, logs.Len(), "a failing hook should not stop the entry from being logged")
+	assert.Contains(t, errorOutput.String(), "broken hook")
+}
@@ -0,0 +1,211 @@
+package httplogging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/smartcontractkit/logger"
+)
+
+// redactedValue replaces the values of the sensitive headers, and of the
+// redacted keys of JSON bodies.
+const redactedValue = "[REDACTED]"
+
+// sensitiveHeaders are the canonical names of the headers always redacted,
+// in addition to those registered with logger.RegisterRedactedKeys.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+}
+
+// DumpHTTP returns middleware logging, at the debug level, the method, URL,
+// headers and body of every request, and the status, headers and body of
+// its response, once it has been served. Bodies are cut to maxBytes, and
+// the values of the sensitive headers, such as Authorization, and of the
+// keys registered with logger.RegisterRedactedKeys are redacted, in JSON
+// bodies as long as they are not cut. The request body is buffered up to
+// maxBytes and replaced, for the handler to read it whole. A maxBytes of 0
+// or less leaves the bodies out.
+//
+// Nothing is buffered when the debug level is disabled.
+func DumpHTTP(l *logger.Logger, maxBytes int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !l.DebugEnabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			reqBody := captureBody(&r.Body, maxBytes)
+			rec := &bodyRecorder{statusRecorder: statusRecorder{ResponseWriter: w, status: http.StatusOK}, max: maxBytes}
+			next.ServeHTTP(rec, r)
+
+			l.WithContext(r.Context()).Debugw("dumped HTTP request",
+				"method", r.Method,
+				"url", r.URL.String(),
+				"headers", redactHeaders(r.Header),
+				"body", reqBody,
+				"status", rec.status,
+				"response_headers", redactHeaders(rec.Header()),
+				"response_body", redactBody(rec.body.Bytes(), rec.cut),
+			)
+		})
+	}
+}
+
+// DumpTransport returns a RoundTripper logging the requests sent through
+// next, http.DefaultTransport if nil, and their responses, as DumpHTTP does
+// for those served. The response body is buffered up to maxBytes and
+// replaced, for the caller to read it whole.
+func DumpTransport(l *logger.Logger, maxBytes int, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return dumpTransport{l: l, max: maxBytes, next: next}
+}
+
+type dumpTransport struct {
+	l    *logger.Logger
+	max  int
+	next http.RoundTripper
+}
+
+func (t dumpTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !t.l.DebugEnabled() {
+		return t.next.RoundTrip(r)
+	}
+	kv := []interface{}{
+		"method", r.Method,
+		"url", r.URL.String(),
+		"headers", redactHeaders(r.Header),
+	}
+	if r.Body != nil && r.GetBody != nil {
+		// The transport reads the original body, a copy is dumped.
+		if body, err := r.GetBody(); err == nil {
+			kv = append(kv, "body", captureBody(&body, t.max))
+			body.Close()
+		}
+	}
+	resp, err := t.next.RoundTrip(r)
+	if err != nil {
+		t.l.WithContext(r.Context()).Debugw("dumped HTTP request", append(kv, "error", err)...)
+		return resp, err
+	}
+	kv = append(kv,
+		"status", resp.StatusCode,
+		"response_headers", redactHeaders(resp.Header),
+		"response_body", captureBody(&resp.Body, t.max),
+	)
+	t.l.WithContext(r.Context()).Debugw("dumped HTTP request", kv...)
+	return resp, nil
+}
+
+// captureBody returns the first max bytes of *body, redacted if they are the
+// whole body, and replaces *body with a reader of the whole body.
+func captureBody(body *io.ReadCloser, max int) string {
+	if max <= 0 || *body == nil || *body == http.NoBody {
+		return ""
+	}
+	prefix, err := ioutil.ReadAll(io.LimitReader(*body, int64(max)+1))
+	*body = readCloser{Reader: io.MultiReader(bytes.NewReader(prefix), *body), Closer: *body}
+	if err != nil {
+		return string(prefix)
+	}
+	cut := len(prefix) > max
+	if cut {
+		prefix = prefix[:max]
+	}
+	return redactBody(prefix, cut)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// redactBody returns body as text, with the values of the redacted keys of
+// JSON bodies masked, unless the body was cut.
+func redactBody(body []byte, cut bool) string {
+	if cut {
+		return string(body) + "…"
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return string(body)
+	}
+	if !redactJSON(v) {
+		return string(body)
+	}
+	redacted, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+// redactJSON masks the values of the redacted keys of the objects in v,
+// reporting whether any was.
+func redactJSON(v interface{}) bool {
+	redacted := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			if logger.IsRedactedKey(k) {
+				v[k] = redactedValue
+				redacted = true
+			} else if redactJSON(value) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if redactJSON(value) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// redactHeaders returns the headers as a map of comma-separated values, with
+// the sensitive ones redacted.
+func redactHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for name, values := range h {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] || logger.IsRedactedKey(name) {
+			headers[name] = redactedValue
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+// bodyRecorder is a statusRecorder also recording the first max bytes of
+// the body written.
+type bodyRecorder struct {
+	statusRecorder
+	max  int
+	body bytes.Buffer
+	cut  bool
+}
+
+func (br *bodyRecorder) Write(b []byte) (int, error) {
+	if room := br.max - br.body.Len(); room < len(b) {
+		if room > 0 {
+			br.body.Write(b[:room])
+		}
+		br.cut = br.max > 0
+	} else {
+		br.body.Write(b)
+	}
+	return br.statusRecorder.Write(b)
+}
@@ -0,0 +1,116 @@
+package httplogging
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/smartcontractkit/logger"
+	"github.com/smartcontractkit/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDumpHTTP(t *testing.T) {
+	logger.RegisterRedactedKeys("dump_secret")
+	l, logs := loggertest.NewTestLogger()
+	var read string
+	handler := DumpHTTP(l, 64)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		read = string(b)
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(strings.Repeat("x", 80)))
+	}))
+
+	body := `{"user":"bob","dump_secret":"hunter2"}`
+	req := httptest.NewRequest(http.MethodPost, "/path?q=1", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, body, read, "the handler reads the whole body")
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "POST", fields["method"])
+	assert.Equal(t, "/path?q=1", fields["url"])
+	assert.Equal(t, map[string]string{"Authorization": "[REDACTED]", "Content-Type": "application/json"}, fields["headers"])
+	assert.Equal(t, `{"dump_secret":"[REDACTED]","user":"bob"}`, fields["body"])
+	assert.Equal(t, int64(http.StatusCreated), fields["status"])
+	assert.Equal(t, map[string]string{"Set-Cookie": "[REDACTED]"}, fields["response_headers"])
+	assert.Equal(t, strings.Repeat("x", 64)+"…", fields["response_body"])
+}
+
+func TestDumpTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		_, _ = w.Write(b)
+	}))
+	defer srv.Close()
+
+	l, logs := loggertest.NewTestLogger()
+	client := &http.Client{Transport: DumpTransport(l, 4, nil)}
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("hello"))
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "hello", string(b), "the caller reads the whole body")
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "POST", fields["method"])
+	assert.Equal(t, "hell…", fields["body"])
+	assert.Equal(t, int64(http.StatusOK), fields["status"])
+	assert.Equal(t, "hell…", fields["response_body"])
+}
+
+func TestDumpHTTP_DebugDisabled(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := logger.NewLogger(zap.New(core))
+	handler := DumpHTTP(l, 32)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Zero(t, logs.Len())
+}
+
+func TestDumpHTTP_NoCapture(t *testing.T) {
+	for _, maxBytes := range []int{0, -1} {
+		l, logs := loggertest.NewTestLogger()
+		var read string
+		handler := DumpHTTP(l, maxBytes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			read = string(b)
+			_, _ = w.Write([]byte("response"))
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("request")))
+
+		assert.Equal(t, "request", read)
+		require.Equal(t, 1, logs.Len())
+		fields := logs.All()[0].ContextMap()
+		assert.Equal(t, "", fields["body"], "maxBytes %d", maxBytes)
+		assert.Equal(t, "", fields["response_body"], "maxBytes %d", maxBytes)
+	}
+}
+
+func TestDumpHTTP_Streaming(t *testing.T) {
+	l, _ := loggertest.NewTestLogger()
+	handler := DumpHTTP(l, 64)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		require.True(t, ok, "the ResponseWriter should still flush")
+		_, _ = w.Write([]byte("event"))
+		f.Flush()
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.True(t, rec.Flushed)
+}
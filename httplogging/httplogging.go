@@ -0,0 +1,83 @@
+// Package httplogging provides net/http middleware logging every request
+// through a logger.Logger.
+package httplogging
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/smartcontractkit/logger"
+)
+
+// RequestLogger returns middleware logging the method, path, status, bytes
+// written and latency of every request once it has been served. Requests are
+// logged at the info level, client errors at warn and server errors at
+// error. The trace ID of the span in the request's context, if any, is logged
+// along.
+func RequestLogger(l *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			rl := l.WithContext(r.Context())
+			log := rl.Infow
+			switch {
+			case rec.status >= http.StatusInternalServerError:
+				log = rl.Errorw
+			case rec.status >= http.StatusBadRequest:
+				log = rl.Warnw
+			}
+			log("served HTTP request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rec.status,
+				"bytes", rec.bytes,
+				"latency", time.Since(start),
+			)
+		})
+	}
+}
+
+// statusRecorder records the status code and the number of bytes written
+// through the wrapped ResponseWriter. It still flushes and hijacks as the
+// wrapped ResponseWriter does, for streamed responses and websocket
+// upgrades, and unwraps to it for http.ResponseController.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	n, err := sr.ResponseWriter.Write(b)
+	sr.bytes += n
+	return n, err
+}
+
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+func (sr *statusRecorder) Flush() {
+	if f, ok := sr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (sr *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := sr.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("httplogging: the ResponseWriter can't be hijacked")
+	}
+	return h.Hijack()
+}
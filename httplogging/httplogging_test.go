@@ -0,0 +1,89 @@
+package httplogging
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/smartcontractkit/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRequestLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantLevel zapcore.Level
+	}{
+		{"ok", http.StatusOK, zapcore.InfoLevel},
+		{"client error", http.StatusNotFound, zapcore.WarnLevel},
+		{"server error", http.StatusBadGateway, zapcore.ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, logs := loggertest.NewTestLogger()
+			handler := RequestLogger(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("hello"))
+			}))
+
+			traceID := trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+			sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{0x01}})
+			req := httptest.NewRequest(http.MethodGet, "/path?q=1", nil)
+			req = req.WithContext(trace.ContextWithSpanContext(req.Context(), sc))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			entries := logs.All()
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.wantLevel, entries[0].Level)
+			fields := entries[0].ContextMap()
+			assert.Equal(t, "GET", fields["method"])
+			assert.Equal(t, "/path", fields["path"])
+			assert.Equal(t, int64(tt.status), fields["status"])
+			assert.Equal(t, int64(5), fields["bytes"])
+			assert.Contains(t, fields, "latency")
+			assert.Equal(t, traceID.String(), fields["TraceID"])
+		})
+	}
+}
+
+func TestRequestLogger_Streaming(t *testing.T) {
+	l, _ := loggertest.NewTestLogger()
+	handler := RequestLogger(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		require.True(t, ok, "the ResponseWriter should still flush")
+		_, _ = w.Write([]byte("event"))
+		f.Flush()
+		_, ok = w.(http.Hijacker)
+		assert.True(t, ok)
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		require.True(t, ok)
+		_, ok = u.Unwrap().(*httptest.ResponseRecorder)
+		assert.True(t, ok)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.True(t, rec.Flushed)
+}
+
+func TestRequestLogger_Hijack(t *testing.T) {
+	l, _ := loggertest.NewTestLogger()
+	srv := httptest.NewServer(RequestLogger(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\n\r\n")
+		_ = buf.Flush()
+	})))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+}
@@ -0,0 +1,172 @@
+// +build !windows
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	stderr "errors"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// defaultJournalSocket is the socket of the native protocol of journald.
+const defaultJournalSocket = "/run/systemd/journal/socket"
+
+// journaldSink writes each JSON entry to journald through its native
+// protocol, as a journal entry with a field for each field of the entry:
+// the message as MESSAGE, the level as the syslog PRIORITY, and the others
+// under their uppercased keys, such as TRACEID.
+type journaldSink struct {
+	// conn is unconnected, for file descriptors to be sent on it.
+	conn    *net.UnixConn
+	journal *net.UnixAddr
+	// identifier is the SYSLOG_IDENTIFIER of the entries, if set.
+	identifier string
+}
+
+// newJournaldSink opens a journald sink for an output path such as
+// journald://?identifier=app, writing to the socket of the local journald
+// unless another is given with the socket query parameter.
+func newJournaldSink(u *url.URL) (zap.Sink, error) {
+	query := u.Query()
+	socket := query.Get("socket")
+	if socket == "" {
+		socket = defaultJournalSocket
+	}
+	if _, err := os.Stat(socket); err != nil {
+		return nil, errors.Wrap(err, "failed to connect to journald")
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to journald")
+	}
+	return journaldSink{
+		conn:       conn,
+		journal:    &net.UnixAddr{Name: socket, Net: "unixgram"},
+		identifier: query.Get("identifier"),
+	}, nil
+}
+
+func (s journaldSink) Write(b []byte) (int, error) {
+	msg := s.journalEntry(b)
+	if _, err := s.conn.WriteToUnix(msg, s.journal); err != nil {
+		if !isMessageTooLong(err) {
+			return 0, err
+		}
+		if err := s.writeLarge(msg); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// journalEntry encodes a JSON entry in the native protocol of journald.
+func (s journaldSink) journalEntry(b []byte) []byte {
+	var buf bytes.Buffer
+	entry := gjson.ParseBytes(b)
+	if !entry.IsObject() {
+		appendJournalField(&buf, "MESSAGE", strings.TrimSuffix(string(b), "\n"))
+		return buf.Bytes()
+	}
+	if s.identifier != "" {
+		appendJournalField(&buf, "SYSLOG_IDENTIFIER", s.identifier)
+	}
+	entry.ForEach(func(key, value gjson.Result) bool {
+		text := value.String()
+		if value.Type != gjson.String {
+			text = value.Raw
+		}
+		switch key.String() {
+		case "msg":
+			appendJournalField(&buf, "MESSAGE", text)
+		case "level":
+			if severity, ok := levelSeverities[strings.ToLower(text)]; ok {
+				appendJournalField(&buf, "PRIORITY", strconv.Itoa(int(severity)))
+			}
+			appendJournalField(&buf, "LEVEL", text)
+		default:
+			if name := journalFieldName(key.String()); name != "" {
+				appendJournalField(&buf, name, text)
+			}
+		}
+		return true
+	})
+	return buf.Bytes()
+}
+
+// writeLarge sends an entry too large for a datagram to journald as a file
+// descriptor, as its native protocol provides for.
+func (s journaldSink) writeLarge(msg []byte) error {
+	f, err := ioutil.TempFile("/dev/shm", "journal")
+	if err != nil {
+		return errors.Wrap(err, "failed to buffer journal entry")
+	}
+	defer f.Close()
+	if err := os.Remove(f.Name()); err != nil {
+		return errors.Wrap(err, "failed to buffer journal entry")
+	}
+	if _, err := f.Write(msg); err != nil {
+		return errors.Wrap(err, "failed to buffer journal entry")
+	}
+	_, _, err = s.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), s.journal)
+	return err
+}
+
+func (journaldSink) Sync() error { return nil }
+
+func (s journaldSink) Close() error { return s.conn.Close() }
+
+// appendJournalField appends a field to a journal entry, in the binary form
+// if value holds newlines.
+func appendJournalField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName returns key as a journal field name, uppercased and made
+// of letters, digits and underscores only, or "" if it has none of them.
+// Leading underscores are dropped, since they mark the fields journald adds
+// itself.
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "F_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// isMessageTooLong reports whether err is a datagram refused for its size.
+func isMessageTooLong(err error) bool {
+	return stderr.Is(err, syscall.EMSGSIZE) || stderr.Is(err, syscall.ENOBUFS)
+}
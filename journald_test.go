@@ -0,0 +1,142 @@
+// +build !windows
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseJournalEntry decodes an entry of the native protocol of journald.
+func parseJournalEntry(t *testing.T, b []byte) map[string]string {
+	fields := map[string]string{}
+	for len(b) > 0 {
+		i := bytes.IndexAny(b, "=\n")
+		require.True(t, i > 0, "malformed entry %q", b)
+		name := string(b[:i])
+		if b[i] == '=' {
+			end := bytes.IndexByte(b, '\n')
+			fields[name] = string(b[i+1 : end])
+			b = b[end+1:]
+			continue
+		}
+		n := binary.LittleEndian.Uint64(b[i+1 : i+9])
+		fields[name] = string(b[i+9 : i+9+int(n)])
+		b = b[i+9+int(n)+1:]
+	}
+	return fields
+}
+
+func listenJournal(t *testing.T) (*net.UnixConn, string) {
+	dir, err := ioutil.TempDir("", "journal")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, socket
+}
+
+func TestJournaldSink(t *testing.T) {
+	conn, socket := listenJournal(t)
+	u, err := url.Parse("journald://?identifier=test&socket=" + url.QueryEscape(socket))
+	require.NoError(t, err)
+	sink, err := newJournaldSink(u)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	read := func() map[string]string {
+		buf := make([]byte, 4096)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		return parseJournalEntry(t, buf[:n])
+	}
+
+	entry := `{"level":"warn","ts":1.5,"caller":"a/b.go:1","msg":"warned","TraceID":"abc","attempt":3,"in.sub-key":{"a":1},"_hidden":true}` + "\n"
+	n, err := sink.Write([]byte(entry))
+	require.NoError(t, err)
+	assert.Equal(t, len(entry), n)
+	assert.Equal(t, map[string]string{
+		"SYSLOG_IDENTIFIER": "test",
+		"MESSAGE":           "warned",
+		"PRIORITY":          "4",
+		"LEVEL":             "warn",
+		"TS":                "1.5",
+		"CALLER":            "a/b.go:1",
+		"TRACEID":           "abc",
+		"ATTEMPT":           "3",
+		"IN_SUB_KEY":        `{"a":1}`,
+		"HIDDEN":            "true",
+	}, read())
+
+	_, err = sink.Write([]byte(`{"level":"error","msg":"failed","stacktrace":"main.main\n\tmain.go:1"}`))
+	require.NoError(t, err)
+	fields := read()
+	assert.Equal(t, "3", fields["PRIORITY"])
+	assert.Equal(t, "main.main\n\tmain.go:1", fields["STACKTRACE"])
+
+	_, err = sink.Write([]byte("not json\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"MESSAGE": "not json"}, read())
+}
+
+func TestJournaldSink_Large(t *testing.T) {
+	conn, socket := listenJournal(t)
+	sink, err := newJournaldSink(&url.URL{Scheme: "journald", RawQuery: "socket=" + url.QueryEscape(socket)})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	msg := strings.Repeat("x", 1<<20)
+	_, err = sink.Write([]byte(`{"level":"info","msg":"` + msg + `"}`))
+	require.NoError(t, err)
+
+	oob := make([]byte, syscall.CmsgSpace(4))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	_, oobn, _, _, err := conn.ReadMsgUnix(nil, oob)
+	require.NoError(t, err)
+	messages, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	fds, err := syscall.ParseUnixRights(&messages[0])
+	require.NoError(t, err)
+	require.Len(t, fds, 1)
+	f := os.NewFile(uintptr(fds[0]), "journal")
+	defer f.Close()
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, msg, parseJournalEntry(t, b)["MESSAGE"])
+}
+
+func TestNewJournaldSink_NoJournal(t *testing.T) {
+	_, err := newJournaldSink(&url.URL{Scheme: "journald", RawQuery: "socket=/nonexistent/socket"})
+	assert.Error(t, err)
+}
+
+func TestJournalFieldName(t *testing.T) {
+	for key, want := range map[string]string{
+		"msg":         "MSG",
+		"TraceID":     "TRACEID",
+		"__internal":  "INTERNAL",
+		"1st":         "F_1ST",
+		"é":           "",
+		"dotted.name": "DOTTED_NAME",
+	} {
+		assert.Equal(t, want, journalFieldName(key), key)
+	}
+}
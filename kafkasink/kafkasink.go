@@ -0,0 +1,184 @@
+// Package kafkasink registers the kafka sink, shipping the entries of the
+// loggers built with a kafka://broker:9092/topic output path to a Kafka
+// topic. The sink is only available to the programs importing the package,
+// which keeps the Kafka client out of the others:
+//
+//	import _ "github.com/smartcontractkit/logger/kafkasink"
+//
+// Several brokers can be listed, separated by commas, as in
+// kafka://broker1:9092,broker2:9092/topic. The batchSize and flushInterval
+// query parameters tune the batching of the entries, which default to
+// batches of 100 entries flushed every second.
+package kafkasink
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	// writeTimeout bounds how long a batch is retried by the Kafka client
+	// before its entries are dropped.
+	writeTimeout = 10 * time.Second
+)
+
+func init() {
+	if err := zap.RegisterSink("kafka", newSink); err != nil {
+		panic(err)
+	}
+}
+
+// messageWriter produces messages to a topic, as kafka.Writer does.
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// newWriter returns the producer of the sink, and is replaced in tests.
+var newWriter = func(brokers []string, topic string, batchSize int) messageWriter {
+	return &kafka.Writer{
+		Addr:     kafka.TCP(brokers...),
+		Topic:    topic,
+		Balancer: &kafka.LeastBytes{},
+		// The sink does its own batching, so the batches it hands over are
+		// written right away.
+		BatchSize:    batchSize,
+		BatchTimeout: time.Millisecond,
+	}
+}
+
+// sink produces every JSON-encoded entry as a message of the topic. The
+// entries are batched, and written when the batch is full, every
+// flushInterval, and on Sync and Close. The error of a periodic flush is
+// returned by the following Write, so that zap reports it to the
+// ErrorOutputPaths of the logger.
+type sink struct {
+	mu        sync.Mutex
+	w         messageWriter
+	batch     []kafka.Message
+	batchSize int
+	err       error
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func newSink(u *url.URL) (zap.Sink, error) {
+	topic := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || topic == "" {
+		return nil, errors.Errorf("invalid kafka output %q: expected kafka://broker:9092/topic", u.String())
+	}
+	query := u.Query()
+	batchSize := defaultBatchSize
+	if s := query.Get("batchSize"); s != "" {
+		var err error
+		if batchSize, err = strconv.Atoi(s); err != nil || batchSize <= 0 {
+			return nil, errors.Errorf("invalid batchSize for kafka output: %q is not a positive integer", s)
+		}
+	}
+	flushInterval := defaultFlushInterval
+	if s := query.Get("flushInterval"); s != "" {
+		var err error
+		if flushInterval, err = time.ParseDuration(s); err != nil || flushInterval <= 0 {
+			return nil, errors.Errorf("invalid flushInterval for kafka output: %q is not a positive duration", s)
+		}
+	}
+
+	s := &sink{
+		w:         newWriter(strings.Split(u.Host, ","), topic, batchSize),
+		batchSize: batchSize,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go s.flushLoop(flushInterval)
+	return s, nil
+}
+
+func (s *sink) flushLoop(flushInterval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			if err := s.flush(); err != nil {
+				s.err = err
+			}
+			s.mu.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *sink) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// zap reuses the buffer of the entry once written.
+	value := make([]byte, len(b))
+	copy(value, b)
+	s.batch = append(s.batch, kafka.Message{Value: value})
+
+	err := s.err
+	s.err = nil
+	if len(s.batch) >= s.batchSize {
+		if flushErr := s.flush(); flushErr != nil {
+			err = flushErr
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// flush writes the pending batch, dropping it whether or not it could be
+// written. The caller must hold s.mu.
+func (s *sink) flush() error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+	batch := s.batch
+	s.batch = nil
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := s.w.WriteMessages(ctx, batch...); err != nil {
+		return errors.Wrapf(err, "failed to ship %d entries to kafka", len(batch))
+	}
+	return nil
+}
+
+// Sync flushes the batch, returning the error of a failed periodic flush
+// not reported by Write yet, if any.
+func (s *sink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.flush()
+	if err == nil {
+		err = s.err
+	}
+	s.err = nil
+	return err
+}
+
+// Close stops the periodic flushes, and flushes the batch one last time
+// before closing the producer.
+func (s *sink) Close() error {
+	close(s.stop)
+	<-s.done
+	err := s.Sync()
+	if closeErr := s.w.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
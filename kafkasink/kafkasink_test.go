@@ -0,0 +1,137 @@
+package kafkasink
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/smartcontractkit/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSink(t *testing.T) {
+	w := useTestWriter(t)
+
+	config := zap.NewProductionConfig()
+	config.OutputPaths = []string{"kafka://broker1:9092,broker2:9092/logs?batchSize=2&flushInterval=1h"}
+	zl, err := config.Build()
+	require.NoError(t, err)
+	l := logger.NewLogger(zl)
+	assert.Equal(t, []string{"broker1:9092", "broker2:9092"}, w.brokers)
+	assert.Equal(t, "logs", w.topic)
+
+	l.Infow("first", "key", "value")
+	assert.Empty(t, w.Batches(), "the entry should still be batched")
+	l.Infow("second")
+	batches := w.Batches()
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 2)
+	assert.Contains(t, string(batches[0][0].Value), `"msg":"first","key":"value"`)
+
+	l.Infow("third")
+	require.NoError(t, l.Close())
+	batches = w.Batches()
+	require.Len(t, batches, 2)
+	assert.Contains(t, string(batches[1][0].Value), `"msg":"third"`)
+}
+
+func TestSink_FlushInterval(t *testing.T) {
+	w := useTestWriter(t)
+	s, err := newSink(&url.URL{Scheme: "kafka", Host: "broker:9092", Path: "/logs", RawQuery: "flushInterval=10ms"})
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, err = s.Write([]byte(`{"msg":"flushed periodically"}`))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(w.Batches()) == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSink_ProduceErrors(t *testing.T) {
+	w := useTestWriter(t)
+	w.err = errors.New("broker unreachable")
+	dir, err := ioutil.TempDir("", "kafkasink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	errorOutput := filepath.Join(dir, "errors.log")
+
+	config := zap.NewProductionConfig()
+	config.OutputPaths = []string{"kafka://broker:9092/logs?batchSize=1"}
+	config.ErrorOutputPaths = []string{errorOutput}
+	zl, err := config.Build()
+	require.NoError(t, err)
+	zl.Info("lost")
+
+	b, err := ioutil.ReadFile(errorOutput)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "failed to ship 1 entries to kafka: broker unreachable")
+}
+
+func TestNewSink_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		url  url.URL
+		want string
+	}{
+		{"no topic", url.URL{Scheme: "kafka", Host: "broker:9092"}, `invalid kafka output "kafka://broker:9092": expected kafka://broker:9092/topic`},
+		{"batchSize", url.URL{Scheme: "kafka", Host: "broker:9092", Path: "/logs", RawQuery: "batchSize=0"}, `invalid batchSize for kafka output: "0" is not a positive integer`},
+		{"flushInterval", url.URL{Scheme: "kafka", Host: "broker:9092", Path: "/logs", RawQuery: "flushInterval=soon"}, `invalid flushInterval for kafka output: "soon" is not a positive duration`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSink(&tt.url)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+// useTestWriter makes the sinks produce to the returned writer for the
+// duration of the test.
+func useTestWriter(t *testing.T) *testWriter {
+	w := &testWriter{}
+	original := newWriter
+	newWriter = func(brokers []string, topic string, _ int) messageWriter {
+		w.brokers, w.topic = brokers, topic
+		return w
+	}
+	t.Cleanup(func() { newWriter = original })
+	return w
+}
+
+type testWriter struct {
+	brokers []string
+	topic   string
+	err     error
+
+	mu      sync.Mutex
+	batches [][]kafka.Message
+}
+
+func (tw *testWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	if tw.err != nil {
+		return tw.err
+	}
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.batches = append(tw.batches, msgs)
+	return nil
+}
+
+func (*testWriter) Close() error { return nil }
+
+func (tw *testWriter) Batches() [][]kafka.Message {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.batches
+}
@@ -3,267 +3,1228 @@
 package logger
 
 import (
-	stderr "errors"
+	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var logger *Logger
+var (
+	// loggerMu guards logger and pkgLogger, which are swapped by SetLogger.
+	loggerMu sync.RWMutex
+	logger   *Logger
+	// pkgLogger wraps the same zap logger as logger with an extra caller
+	// skip, so entries logged through the package-level functions report
+	// the caller of those functions, and the global fields.
+	pkgLogger *Logger
+	// level is shared by every zap logger built by this package, so that
+	// it can be changed while the process is running.
+	level = zap.NewAtomicLevel()
+)
 
 func init() {
-	err := zap.RegisterSink("pretty", prettyConsoleSink(os.Stderr))
+	err := zap.RegisterSink("pretty", prettyConsoleSink)
 	if err != nil {
-		fatalLineCounter.Inc()
+		getCounters().fatalLines.Inc()
 		log.Fatalf("failed to register pretty printer %+v", err)
 	}
+	err = zap.RegisterSink("prettyjson", prettyJSONSink)
+	if err != nil {
+		getCounters().fatalLines.Inc()
+		log.Fatalf("failed to register pretty json printer %+v", err)
+	}
+	err = zap.RegisterSink("rotate", newRotatingSink)
+	if err != nil {
+		getCounters().fatalLines.Inc()
+		log.Fatalf("failed to register rotating file sink %+v", err)
+	}
+	err = zap.RegisterSink("buffered", newBufferedSink)
+	if err != nil {
+		getCounters().fatalLines.Inc()
+		log.Fatalf("failed to register buffered sink %+v", err)
+	}
+	err = zap.RegisterSink("recent", newRecentSink)
+	if err != nil {
+		getCounters().fatalLines.Inc()
+		log.Fatalf("failed to register recent logs sink %+v", err)
+	}
 	err = registerOSSinks()
 	if err != nil {
-		fatalLineCounter.Inc()
+		getCounters().fatalLines.Inc()
 		log.Fatalf("failed to register os specific sinks %+v", err)
 	}
 
-	var level zapcore.Level
-	err = level.UnmarshalText([]byte(os.Getenv("LOG_LEVEL")))
-	if err != nil {
-		fatalLineCounter.Inc()
-		log.Fatal(err)
-	}
+	lvl, levelErr := envLogLevel()
+	level.SetLevel(lvl)
 
 	config := zap.NewProductionConfig()
-	config.Level = zap.NewAtomicLevelAt(level)
-	zl, err := config.Build(zap.AddCallerSkip(1))
+	config.Level = level
+	l, err := buildLogger(config)
 	if err != nil {
-		fatalLineCounter.Inc()
+		getCounters().fatalLines.Inc()
 		log.Fatal(err)
 	}
 
-	SetLogger(zl)
+	ReplaceLogger(l)
+	if levelErr != nil {
+		Warnf("%v, defaulting to %s", levelErr, lvl)
+	}
 }
 
+// envLogLevel parses the LOG_LEVEL environment variable, returning
+// InfoLevel when it is unset. An unparseable value is reported along with
+// InfoLevel rather than treated as fatal, so importing the package is safe
+// without any environment configured.
+func envLogLevel() (zapcore.Level, error) {
+	text := os.Getenv("LOG_LEVEL")
+	if text == "" {
+		return zapcore.InfoLevel, nil
+	}
+	lvl, err := parseLevel(text)
+	if err != nil {
+		return zapcore.InfoLevel, errors.Wrap(err, "invalid LOG_LEVEL")
+	}
+	return lvl, nil
+}
+
+// GetLogger returns the logger used by the package-level functions.
 func GetLogger() *Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
 	return logger
 }
 
-func prettyConsoleSink(s zap.Sink) func(*url.URL) (zap.Sink, error) {
-	return func(*url.URL) (zap.Sink, error) {
-		return PrettyConsole{s}, nil
+// packageLogger returns the logger the package-level functions log through.
+// The package-level functions only delegate to the methods of this logger,
+// which count the lines they log, so as to count every line exactly once.
+func packageLogger() *Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return pkgLogger
+}
+
+// prettyConsoleSink opens a PrettyConsole for the pretty://stdout output
+// path on stdout, and for any other pretty:// path, such as the
+// pretty://console of the production constructors, on stderr.
+//
+// The comma-separated fields query parameter, and the verbose one, set the
+// Fields and Verbose of the PrettyConsole, as WithPrettyFields does, and
+// the maxValueWidth and maxMessageWidth ones its truncation, as
+// WithPrettyTruncation does.
+func prettyConsoleSink(u *url.URL) (zap.Sink, error) {
+	pc := PrettyConsole{Sink: consoleSink{os.Stderr}}
+	if u.Host == "stdout" {
+		pc.Sink = consoleSink{os.Stdout}
+	}
+	query := u.Query()
+	if fields := query.Get("fields"); fields != "" {
+		pc.Fields = strings.Split(fields, ",")
 	}
+	if verbose := query.Get("verbose"); verbose != "" {
+		var err error
+		if pc.Verbose, err = strconv.ParseBool(verbose); err != nil {
+			return nil, errors.Wrap(err, "invalid verbose for pretty output")
+		}
+	}
+	widths := []struct {
+		param string
+		width *int
+	}{
+		{"maxValueWidth", &pc.MaxValueWidth},
+		{"maxMessageWidth", &pc.MaxMessageWidth},
+	}
+	for _, w := range widths {
+		if v := query.Get(w.param); v != "" {
+			var err error
+			if *w.width, err = strconv.Atoi(v); err != nil {
+				return nil, errors.Wrapf(err, "invalid %s for pretty output", w.param)
+			}
+		}
+	}
+	return pc, nil
 }
 
+// consoleSink is stdout or stderr as a zap.Sink, which closing a logger
+// leaves open.
+type consoleSink struct {
+	*os.File
+}
+
+func (consoleSink) Close() error { return nil }
+
 // Logger holds a field for the logger interface.
 type Logger struct {
 	*zap.SugaredLogger
+	// levels reports the levels the logger writes, so that lines are only
+	// counted when they are actually logged.
+	levels zapcore.LevelEnabler
+	// name is the name of the logger, as set by Named.
+	name string
+	// closer releases the sinks of the logger, nil when not built by the
+	// constructors of this package.
+	closer *closer
+	// errorOutput is where the errors of the logger itself are reported, as
+	// zap reports write errors, nil for stderr when not built by the
+	// constructors of this package.
+	errorOutput zapcore.WriteSyncer
+}
+
+// NewLogger returns a Logger writing through zl. As with SetLogger, zl
+// should be built with zap.AddCallerSkip(1) to report the right callers.
+func NewLogger(zl *zap.Logger) *Logger {
+	return &Logger{SugaredLogger: zl.Sugar(), levels: zl.Core()}
+}
+
+// NewNopLogger returns a Logger that discards everything it is given and
+// never counts any lines.
+func NewNopLogger() *Logger {
+	return NewLogger(zap.NewNop())
+}
+
+// enabled reports whether entries at lvl are written by the logger.
+func (l *Logger) enabled(lvl zapcore.Level) bool {
+	if l.levels == nil {
+		// Not built by NewLogger.
+		return l.Desugar().Core().Enabled(lvl)
+	}
+	if named, ok := l.levels.(interface {
+		enabledFor(name string, lvl zapcore.Level) bool
+	}); ok {
+		return named.enabledFor(l.name, lvl)
+	}
+	return l.levels.Enabled(lvl)
+}
+
+// DebugEnabled reports whether entries at the debug level are written, so
+// that callers can skip building costly arguments that would be dropped.
+func (l *Logger) DebugEnabled() bool {
+	return l.enabled(zapcore.DebugLevel)
+}
+
+// InfoEnabled reports whether entries at the info level are written.
+func (l *Logger) InfoEnabled() bool {
+	return l.enabled(zapcore.InfoLevel)
+}
+
+// WarnEnabled reports whether entries at the warn level are written.
+func (l *Logger) WarnEnabled() bool {
+	return l.enabled(zapcore.WarnLevel)
+}
+
+// ErrorEnabled reports whether entries at the error level are written.
+func (l *Logger) ErrorEnabled() bool {
+	return l.enabled(zapcore.ErrorLevel)
 }
 
 // Write logs a message at the Info level and returns the length
-// of the given bytes.
+// of the given bytes. A trailing newline, as added by the standard
+// library's log package, is dropped from the message.
 func (l *Logger) Write(b []byte) (int, error) {
-	l.Info(string(b))
+	l.Info(strings.TrimSuffix(string(b), "\n"))
 	return len(b), nil
 }
 
-// SetLogger sets the internal logger to the given input.
-func SetLogger(zl *zap.Logger) {
-	if logger != nil {
-		defer func() {
-			if err := logger.Sync(); err != nil {
-				if stderr.Unwrap(err).Error() != os.ErrInvalid.Error() &&
-					stderr.Unwrap(err).Error() != "inappropriate ioctl for device" &&
-					stderr.Unwrap(err).Error() != "bad file descriptor" {
-					fatalLineCounter.Inc()
-					// logger.Sync() will return 'invalid argument' error when closing file
-					log.Fatalf("failed to sync logger %+v", err)
-				}
+// Fields the OpenTelemetry trace and span IDs are logged under.
+const (
+	TraceId = "TraceID"
+	SpanId  = "SpanID"
+)
+
+// WithSpan returns a child logger that adds the trace and span IDs of the
+// given span to every subsequent entry, see also EnableDatadogCorrelation.
+// The receiver is left unchanged.
+func (l *Logger) WithSpan(span trace.Span) *Logger {
+	return l.With(spanKeysAndValues(span.SpanContext())...)
+}
+
+// WithContext returns a child logger that adds the trace and span IDs of the
+// span stored in ctx to every subsequent entry, along with the members of
+// the baggage of ctx named by baggageKeys, under their own keys. If ctx
+// carries neither a valid span nor any of those members the receiver itself
+// is returned.
+func (l *Logger) WithContext(ctx context.Context, baggageKeys ...string) *Logger {
+	var kv []interface{}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		kv = append(kv, spanKeysAndValues(sc)...)
+	}
+	if len(baggageKeys) > 0 {
+		b := baggage.FromContext(ctx)
+		for _, key := range baggageKeys {
+			if m := b.Member(key); m.Key() != "" {
+				kv = append(kv, key, m.Value())
 			}
-		}()
+		}
+	}
+	if len(kv) == 0 {
+		return l
+	}
+	return l.With(kv...)
+}
+
+// With returns a child logger that adds the given key value pairs to every
+// subsequent entry. Fields added to the child do not affect the receiver.
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	return l.child(l.SugaredLogger.With(redact(keysAndValues)...))
+}
+
+// WithCallerSkip returns a child logger reporting as the caller of every
+// subsequent entry the function n frames further up the stack, so that
+// helpers wrapping the logger can report the location of their own callers.
+func (l *Logger) WithCallerSkip(n int) *Logger {
+	return l.child(l.Desugar().WithOptions(zap.AddCallerSkip(n)).Sugar())
+}
+
+// WithoutCaller returns a child logger that does not annotate subsequent
+// entries with the file and line they were logged from.
+func (l *Logger) WithoutCaller() *Logger {
+	return l.child(l.Desugar().WithOptions(zap.WithCaller(false)).Sugar())
+}
+
+// Sugared returns the zap logger l writes through, for libraries taking a
+// *zap.SugaredLogger; its Desugar returns the *zap.Logger. Entries logged
+// directly through them still go through the cores of l, and so its level,
+// outputs and log_bytes_total counter, but skip what the methods of l add:
+// the redaction of RegisterRedactedKeys, the log_lines_total counter, and
+// the caller adjustment, which l.WithCallerSkip(-1).Sugared() undoes.
+func (l *Logger) Sugared() *zap.SugaredLogger {
+	return l.SugaredLogger
+}
+
+// Named returns a child logger tagging every subsequent entry with the given
+// component name. Names compose, so Named("a").Named("b") is named "a.b".
+// See SetNamedLevel to log the entries of a name at a level of its own.
+func (l *Logger) Named(name string) *Logger {
+	child := l.child(l.SugaredLogger.Named(name))
+	if name != "" {
+		if child.name == "" {
+			child.name = name
+		} else {
+			child.name += "." + name
+		}
+	}
+	return child
+}
+
+// child returns a logger writing through sl, derived from l, with the
+// levels, name and sinks of l.
+func (l *Logger) child(sl *zap.SugaredLogger) *Logger {
+	child := *l
+	child.SugaredLogger = sl
+	return &child
+}
+
+// Trace logs a message at the trace level, below the debug level.
+func (l *Logger) Trace(args ...interface{}) {
+	l.trace(fmt.Sprint(args...), nil)
+}
+
+// Tracef formats and then logs the message at the trace level.
+func (l *Logger) Tracef(format string, values ...interface{}) {
+	l.trace(fmt.Sprintf(format, values...), nil)
+}
+
+// Tracew logs a message and any additional given information at the trace
+// level.
+func (l *Logger) Tracew(msg string, keysAndValues ...interface{}) {
+	l.trace(msg, keysAndValues)
+}
+
+// trace logs at the trace level, which the SugaredLogger has no methods
+// for. It is called by the Trace methods, so that the callers of those are
+// reported, as by the SugaredLogger.
+func (l *Logger) trace(msg string, keysAndValues []interface{}) {
+	if !l.enabled(TraceLevel) {
+		return
+	}
+	zl := l.Desugar()
+	if len(keysAndValues) > 0 {
+		zl = l.SugaredLogger.With(redact(keysAndValues)...).Desugar()
+	}
+	if ce := zl.WithOptions(zap.AddCallerSkip(1)).Check(TraceLevel, msg); ce != nil {
+		ce.Write()
+	}
+	getCounters().traceLines.Inc()
+}
+
+// Debug logs a debug message.
+func (l *Logger) Debug(args ...interface{}) {
+	if !l.enabled(zapcore.DebugLevel) {
+		return
+	}
+	l.SugaredLogger.Debug(args...)
+	getCounters().debugLines.Inc()
+}
+
+// Debugf formats and then logs the message.
+func (l *Logger) Debugf(format string, values ...interface{}) {
+	if !l.enabled(zapcore.DebugLevel) {
+		return
+	}
+	l.SugaredLogger.Debugf(format, values...)
+	getCounters().debugLines.Inc()
+}
+
+// Debugw logs a debug message and any additional given information.
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(zapcore.DebugLevel) {
+		return
+	}
+	l.SugaredLogger.Debugw(msg, redact(keysAndValues)...)
+	getCounters().debugLines.Inc()
+}
+
+// Info logs an info message.
+func (l *Logger) Info(args ...interface{}) {
+	if !l.enabled(zapcore.InfoLevel) {
+		return
+	}
+	l.SugaredLogger.Info(args...)
+	getCounters().infoLines.Inc()
+}
+
+// Infof formats and then logs the message.
+func (l *Logger) Infof(format string, values ...interface{}) {
+	if !l.enabled(zapcore.InfoLevel) {
+		return
+	}
+	l.SugaredLogger.Infof(format, values...)
+	getCounters().infoLines.Inc()
+}
+
+// Infow logs an info message and any additional given information.
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(zapcore.InfoLevel) {
+		return
+	}
+	l.SugaredLogger.Infow(msg, redact(keysAndValues)...)
+	getCounters().infoLines.Inc()
+}
+
+// Warn logs a message at the warn level.
+func (l *Logger) Warn(args ...interface{}) {
+	if !l.enabled(zapcore.WarnLevel) {
+		return
+	}
+	l.SugaredLogger.Warn(args...)
+	getCounters().warnLines.Inc()
+}
+
+// Warnf formats and then logs the message as Warn.
+func (l *Logger) Warnf(format string, values ...interface{}) {
+	if !l.enabled(zapcore.WarnLevel) {
+		return
+	}
+	l.SugaredLogger.Warnf(format, values...)
+	getCounters().warnLines.Inc()
+}
+
+// Warnw logs a warn message and any additional given information.
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(zapcore.WarnLevel) {
+		return
+	}
+	l.SugaredLogger.Warnw(msg, redact(keysAndValues)...)
+	getCounters().warnLines.Inc()
+}
+
+// Error logs an error message.
+func (l *Logger) Error(args ...interface{}) {
+	if !l.enabled(zapcore.ErrorLevel) {
+		return
+	}
+	l.SugaredLogger.Error(args...)
+	getCounters().errorLines.Inc()
+}
+
+// Errorf logs a message at the error level using Sprintf.
+func (l *Logger) Errorf(format string, values ...interface{}) {
+	if !l.enabled(zapcore.ErrorLevel) {
+		return
+	}
+	l.SugaredLogger.Errorf(format, values...)
+	getCounters().errorLines.Inc()
+}
+
+// Errorw logs an error message and any additional given information. Loggers
+// built by the constructors of this package include a stack trace, unless
+// built WithoutStacktraces.
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(zapcore.ErrorLevel) {
+		return
+	}
+	l.SugaredLogger.Errorw(msg, redact(keysAndValues)...)
+	getCounters().errorLines.Inc()
+}
+
+// DPanic logs a message at the dpanic level, panicking if the logger is in
+// development mode, see WithDevelopment.
+func (l *Logger) DPanic(args ...interface{}) {
+	if l.enabled(zapcore.DPanicLevel) {
+		getCounters().dPanicLines.Inc()
+	}
+	l.SugaredLogger.DPanic(args...)
+}
+
+// DPanicf formats and then logs the message at the dpanic level, panicking
+// if the logger is in development mode, see WithDevelopment.
+func (l *Logger) DPanicf(format string, values ...interface{}) {
+	if l.enabled(zapcore.DPanicLevel) {
+		getCounters().dPanicLines.Inc()
+	}
+	l.SugaredLogger.DPanicf(format, values...)
+}
+
+// DPanicw logs a message and any additional given information at the dpanic
+// level, panicking if the logger is in development mode, see WithDevelopment.
+func (l *Logger) DPanicw(msg string, keysAndValues ...interface{}) {
+	if l.enabled(zapcore.DPanicLevel) {
+		getCounters().dPanicLines.Inc()
+	}
+	l.SugaredLogger.DPanicw(msg, redact(keysAndValues)...)
+}
+
+// Panic logs a panic message then panics.
+func (l *Logger) Panic(args ...interface{}) {
+	if l.enabled(zapcore.PanicLevel) {
+		getCounters().panicLines.Inc()
+	}
+	l.SugaredLogger.Panic(args...)
+}
+
+// Panicf formats and then logs the message before panicking.
+func (l *Logger) Panicf(format string, values ...interface{}) {
+	if l.enabled(zapcore.PanicLevel) {
+		getCounters().panicLines.Inc()
+	}
+	l.SugaredLogger.Panicf(format, values...)
+}
+
+// Panicw logs a panic message and any additional given information, then
+// panics.
+func (l *Logger) Panicw(msg string, keysAndValues ...interface{}) {
+	if l.enabled(zapcore.PanicLevel) {
+		getCounters().panicLines.Inc()
+	}
+	l.SugaredLogger.Panicw(msg, redact(keysAndValues)...)
+}
+
+// Fatal logs a fatal message then exits the application.
+func (l *Logger) Fatal(args ...interface{}) {
+	if l.enabled(zapcore.FatalLevel) {
+		getCounters().fatalLines.Inc()
+	}
+	l.SugaredLogger.Fatal(args...)
+}
+
+// Fatalf logs a message at the fatal level using Sprintf.
+func (l *Logger) Fatalf(format string, values ...interface{}) {
+	if l.enabled(zapcore.FatalLevel) {
+		getCounters().fatalLines.Inc()
+	}
+	l.SugaredLogger.Fatalf(format, values...)
+}
+
+// Fatalw logs a fatal message and any additional given information, then
+// exits the application.
+func (l *Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	if l.enabled(zapcore.FatalLevel) {
+		getCounters().fatalLines.Inc()
+	}
+	l.SugaredLogger.Fatalw(msg, redact(keysAndValues)...)
+}
+
+// WarnIf logs the error if present.
+func (l *Logger) WarnIf(err error) {
+	if err != nil && l.enabled(zapcore.WarnLevel) {
+		l.SugaredLogger.Warnw(err.Error(), errorVerbose(err)...)
+		getCounters().warnLines.Inc()
+	}
+}
+
+// ErrorIf logs the error if present.
+func (l *Logger) ErrorIf(err error, optionalMsg ...string) {
+	if err != nil && l.enabled(zapcore.ErrorLevel) {
+		if len(optionalMsg) > 0 {
+			err = errors.Wrap(err, optionalMsg[0])
+		}
+		l.SugaredLogger.Errorw(err.Error(), errorVerbose(err)...)
+		getCounters().errorLines.Inc()
+	}
+}
+
+// ErrorIfError is ErrorIf without a message, for hot paths. When err is
+// nil, both cost a nil check and neither allocates, as the optional message
+// slice doesn't escape; see BenchmarkLogger_ErrorIf_NilError.
+func (l *Logger) ErrorIfError(err error) {
+	if err != nil && l.enabled(zapcore.ErrorLevel) {
+		l.SugaredLogger.Errorw(err.Error(), errorVerbose(err)...)
+		getCounters().errorLines.Inc()
+	}
+}
+
+// ErrorIfw logs msg with the error under the "error" key and any additional
+// given information, if the error is present.
+func (l *Logger) ErrorIfw(err error, msg string, keysAndValues ...interface{}) {
+	if err != nil && l.enabled(zapcore.ErrorLevel) {
+		kv := redact(keysAndValues)
+		l.SugaredLogger.Errorw(msg, append(kv[:len(kv):len(kv)], zap.Error(err))...)
+		getCounters().errorLines.Inc()
 	}
-	logger = &Logger{zl.Sugar()}
+}
+
+// LogError logs msg with the error under the "error" key, if the error is
+// present, and returns the error, for call sites to log and propagate it in
+// one statement, as in return l.LogError(err, "failed to connect").
+func (l *Logger) LogError(err error, msg string) error {
+	if err != nil && l.enabled(zapcore.ErrorLevel) {
+		l.SugaredLogger.Errorw(msg, zap.Error(err))
+		getCounters().errorLines.Inc()
+	}
+	return err
+}
+
+// ErrorCode is the field the codes of ErrorCoded are logged under.
+const ErrorCode = "error_code"
+
+// maxErrorCodes is the number of distinct codes ErrorCoded counts under
+// their own label.
+const maxErrorCodes = 256
+
+var errorCodes = newBoundedLabelValues(maxErrorCodes)
+
+// ErrorCoded logs err, if present, with its code under the "error_code" key
+// and any additional given information, and counts it by code in the
+// log_error_codes_total counter. Codes should be of a fixed set, such as
+// "E1023", though at most maxErrorCodes distinct codes are counted under
+// their own label, and the others together under the _other label.
+func (l *Logger) ErrorCoded(code string, err error, keysAndValues ...interface{}) {
+	if err != nil && l.enabled(zapcore.ErrorLevel) {
+		kv := append([]interface{}{ErrorCode, code}, redact(keysAndValues)...)
+		l.SugaredLogger.Errorw(err.Error(), append(kv, errorVerbose(err)...)...)
+		getCounters().errorLines.Inc()
+		getCounters().errorCodes.WithLabelValues(errorCodes.label(code)).Inc()
+	}
+}
+
+// ErrorIfCalling calls the given function and logs the error of it if there is.
+// The error is prefixed with optionalMsg if given, or else with the name of
+// the function.
+func (l *Logger) ErrorIfCalling(f func() error, optionalMsg ...string) {
+	err := callingError("ErrorIfCalling", f, optionalMsg)
+	if err != nil && l.enabled(zapcore.ErrorLevel) {
+		l.SugaredLogger.Errorw(err.Error(), errorVerbose(err)...)
+		getCounters().errorLines.Inc()
+	}
+}
+
+// WarnIfCalling is like ErrorIfCalling, but logs at the warn level, for
+// errors that are not worth an error-level event such as those of cleanup.
+func (l *Logger) WarnIfCalling(f func() error, optionalMsg ...string) {
+	err := callingError("WarnIfCalling", f, optionalMsg)
+	if err != nil && l.enabled(zapcore.WarnLevel) {
+		l.SugaredLogger.Warnw(err.Error(), errorVerbose(err)...)
+		getCounters().warnLines.Inc()
+	}
+}
+
+// InfoIfCalling is like ErrorIfCalling, but logs at the info level.
+func (l *Logger) InfoIfCalling(f func() error, optionalMsg ...string) {
+	err := callingError("InfoIfCalling", f, optionalMsg)
+	if err != nil && l.enabled(zapcore.InfoLevel) {
+		l.SugaredLogger.Infow(err.Error(), errorVerbose(err)...)
+		getCounters().infoLines.Inc()
+	}
+}
+
+// callingError calls f and returns its error wrapped as described by
+// ErrorIfCalling, or an error naming caller if f is nil.
+func callingError(caller string, f func() error, optionalMsg []string) error {
+	if f == nil {
+		return errors.New(caller + " called with a nil function")
+	}
+	err := f()
+	if err == nil {
+		return nil
+	}
+	if len(optionalMsg) > 0 {
+		return errors.Wrap(err, optionalMsg[0])
+	}
+	return errors.Wrap(err, funcName(f))
+}
+
+// errorVerbose returns the "errorVerbose" field zap adds for structured
+// errors, holding the %+v of err, so that the message-only helpers such as
+// ErrorIf surface the causes and stack of errors.Wrap chains too.
+func errorVerbose(err error) []interface{} {
+	if _, ok := err.(fmt.Formatter); !ok {
+		return nil
+	}
+	return []interface{}{zap.String("errorVerbose", fmt.Sprintf("%+v", err))}
+}
+
+// closureName matches the names the compiler gives anonymous functions.
+var closureName = regexp.MustCompile(`\.func\d+(\.\d+)*$`)
+
+// funcName returns the name of f qualified by its package name, rather than
+// its full import path. Method values are named after the method, and
+// closures are followed by the location they are defined at.
+func funcName(f interface{}) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return "unknown function"
+	}
+	name := strings.TrimSuffix(fn.Name(), "-fm")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if closureName.MatchString(name) {
+		file, line := fn.FileLine(fn.Entry())
+		name = fmt.Sprintf("%s (%s:%d)", name, filepath.Base(file), line)
+	}
+	return name
+}
+
+// PanicIf logs the error if present, wrapped with the optional message as
+// ErrorIf does, then panics with the message of the entry.
+func (l *Logger) PanicIf(err error, optionalMsg ...string) {
+	if err != nil {
+		if len(optionalMsg) > 0 {
+			err = errors.Wrap(err, optionalMsg[0])
+		}
+		if l.enabled(zapcore.PanicLevel) {
+			getCounters().panicLines.Inc()
+		}
+		l.SugaredLogger.Panicw(err.Error(), errorVerbose(err)...)
+	}
+}
+
+// PanicIfw logs msg with the error under the "error" key and any additional
+// given information, if the error is present, then panics with msg.
+func (l *Logger) PanicIfw(err error, msg string, keysAndValues ...interface{}) {
+	if err != nil {
+		if l.enabled(zapcore.PanicLevel) {
+			getCounters().panicLines.Inc()
+		}
+		kv := redact(keysAndValues)
+		l.SugaredLogger.Panicw(msg, append(kv[:len(kv):len(kv)], zap.Error(err))...)
+	}
+}
+
+// SetLogger sets the internal logger to the given input. It is safe to call
+// while other goroutines are logging through the package-level functions.
+func SetLogger(zl *zap.Logger) {
+	ReplaceLogger(NewLogger(zl))
+}
+
+// ReplaceLogger sets the internal logger to l, as SetLogger does, keeping
+// the sinks l was built with by the constructors of this package for the
+// package-level Close to release them. The replaced logger is synced, and
+// its errors reported to the error output of l.
+func ReplaceLogger(l *Logger) {
+	loggerMu.Lock()
+	old := logger
+	logger = l
+	pkgLogger = newPackageLogger(logger)
+	loggerMu.Unlock()
+
+	// The sinks of a closed logger can't be synced anymore, and the errors
+	// of syncing the consoles are already dropped by Logger.Sync.
+	if old != nil && !old.isClosed() {
+		if err := old.Sync(); err != nil {
+			l.reportError("failed to sync the replaced logger", err)
+		}
+	}
+}
+
+// reportError reports err, which is not about any entry, to the error output
+// of l.
+func (l *Logger) reportError(msg string, err error) {
+	out := l.errorOutput
+	if out == nil {
+		out = zapcore.Lock(os.Stderr)
+	}
+	fmt.Fprintf(out, "%v %s: %v\n", time.Now(), msg, err)
+	_ = out.Sync()
+}
+
+// WrapPackageCore replaces the package logger with a child writing through
+// the core returned by wrap, which is handed the core of the current
+// package logger. The child keeps its name, named levels and sinks, so
+// that SetNamedLevel and Close still apply to it. It is how extensions such
+// as sentrylog tee the package logger to another destination.
+func WrapPackageCore(wrap func(zapcore.Core) zapcore.Core) {
+	l := GetLogger()
+	ReplaceLogger(l.child(l.Desugar().WithOptions(zap.WrapCore(wrap)).Sugar()))
 }
 
 // CreateProductionLogger returns a log config for the passed directory
 // with the given LogLevel and customizes stdout for pretty printing.
+// The level is shared with the package, see SetLogLevel.
+//
+// NewProductionLogger is preferred, as it returns a Logger and an error
+// rather than exiting when the logger cannot be built. As with it, a log
+// file that can't be opened only leaves the logger logging to the console.
 func CreateProductionLogger(
-	dir string, jsonConsole bool, lvl zapcore.Level, toDisk bool) *zap.Logger {
-	config := zap.NewProductionConfig()
-	if !jsonConsole {
-		config.OutputPaths = []string{"pretty://console"}
+	dir string, jsonConsole bool, lvl zapcore.Level, toDisk bool, opts ...Option) *zap.Logger {
+	l, err := buildProductionLogger(dir, jsonConsole, lvl, toDisk, opts)
+	if err != nil && l == nil {
+		getCounters().fatalLines.Inc()
+		log.Fatal(err)
 	}
+	return l.Desugar()
+}
+
+// NewProductionLogger returns a Logger for the passed directory with the
+// given LogLevel, customizing stdout for pretty printing unless jsonConsole
+// is set, and also writing to a file in dir when toDisk is set.
+// The level is shared with the package, see SetLogLevel.
+//
+// If the file in dir can't be opened, such as when dir isn't writable, the
+// returned Logger logs to the console only, where it warns about it, and a
+// *LogFileError is returned along with it.
+func NewProductionLogger(
+	dir string, jsonConsole bool, lvl zapcore.Level, toDisk bool, opts ...Option) (*Logger, error) {
+	return buildProductionLogger(dir, jsonConsole, lvl, toDisk, opts)
+}
+
+func buildProductionLogger(
+	dir string, jsonConsole bool, lvl zapcore.Level, toDisk bool, opts []Option) (*Logger, error) {
+	config := productionConfig(jsonConsole, lvl)
+	var fileErr *LogFileError
 	if toDisk {
 		destination := logFileURI(dir)
+		if err := checkOutputPath(destination); err != nil {
+			fileErr = &LogFileError{Dir: dir, Err: err}
+		} else {
+			config.OutputPaths = append(config.OutputPaths, destination)
+			config.ErrorOutputPaths = append(config.ErrorOutputPaths, destination)
+		}
+	}
+	applyOptions(&config, opts)
+	l, err := buildLogger(config)
+	if err != nil {
+		return nil, err
+	}
+	if fileErr != nil {
+		l.Warnw("log file unavailable, logging to the console only", "dir", dir, "err", fileErr.Err)
+		return l, fileErr
+	}
+	return l, nil
+}
+
+// LogFileError is the error of a log file that can't be opened, in which
+// case the logger is built without it.
+type LogFileError struct {
+	// Dir is the directory of the log file.
+	Dir string
+	Err error
+}
+
+func (e *LogFileError) Error() string {
+	return "log file unavailable in " + e.Dir + ": " + e.Err.Error()
+}
+
+// Cause returns the error opening the log file.
+func (e *LogFileError) Cause() error { return e.Err }
+
+// Unwrap returns the error opening the log file.
+func (e *LogFileError) Unwrap() error { return e.Err }
+
+// checkOutputPath reports the error opening the output path, as zap.Open
+// would, if any.
+func checkOutputPath(path string) error {
+	_, closeOutput, err := zap.Open(path)
+	if err != nil {
+		return err
+	}
+	closeOutput()
+	return nil
+}
+
+// NewMirroredProductionLogger is like NewProductionLogger with toDisk set,
+// except that every entry is written to a file in each of dirs, such as two
+// mount points mirroring the logs. The directories whose file can't be
+// opened, such as an unavailable mount, are left out and reported in a
+// warning logged by the returned Logger, which only fails to be built when
+// none of them is available.
+func NewMirroredProductionLogger(
+	dirs []string, jsonConsole bool, lvl zapcore.Level, opts ...Option) (*Logger, error) {
+	config := productionConfig(jsonConsole, lvl)
+	var unavailable []struct {
+		dir string
+		err error
+	}
+	for _, dir := range dirs {
+		destination := logFileURI(dir)
+		if err := checkOutputPath(destination); err != nil {
+			unavailable = append(unavailable, struct {
+				dir string
+				err error
+			}{dir, err})
+			continue
+		}
 		config.OutputPaths = append(config.OutputPaths, destination)
 		config.ErrorOutputPaths = append(config.ErrorOutputPaths, destination)
 	}
-	config.Level.SetLevel(lvl)
-
-	zl, err := config.Build(zap.AddCallerSkip(1))
+	if len(unavailable) == len(dirs) {
+		return nil, errors.Errorf("no log directory available of %v", dirs)
+	}
+	applyOptions(&config, opts)
+	l, err := buildLogger(config)
 	if err != nil {
-		fatalLineCounter.Inc()
-		log.Fatal(err)
+		return nil, err
+	}
+	for _, u := range unavailable {
+		l.Warnw("log directory unavailable, not mirroring logs to it", "dir", u.dir, "err", u.err)
+	}
+	return l, nil
+}
+
+// NewConsoleAndFileLogger returns a Logger writing pretty output to the
+// console and JSON to a file in dir, through two cores composed with
+// zapcore.NewTee. The level is shared with the package, see SetLogLevel.
+func NewConsoleAndFileLogger(dir string, lvl zapcore.Level, opts ...Option) (*Logger, error) {
+	config := productionConfig(false, lvl)
+	destination := logFileURI(dir)
+	consolePaths := config.OutputPaths
+	config.OutputPaths = append(consolePaths[:len(consolePaths):len(consolePaths)], destination)
+	config.ErrorOutputPaths = append(config.ErrorOutputPaths, destination)
+	applyOptions(&config, opts)
+	return buildRoutedLogger(config, []route{
+		{paths: config.OutputPaths[:len(consolePaths)]},
+		{paths: config.OutputPaths[len(consolePaths):]},
+	})
+}
+
+// NewSplitLevelLogger returns a Logger writing JSON entries below the warn
+// level to the output path low, and the others to high, for example
+// "stdout" and "stderr", through two cores composed with zapcore.NewTee.
+// Either path may be a pretty:// one, such as pretty://stdout, for pretty
+// printing. The level is shared with the package, see SetLogLevel.
+func NewSplitLevelLogger(low, high string, lvl zapcore.Level, opts ...Option) (*Logger, error) {
+	return newSplitLevelLogger(low, high, zapcore.WarnLevel, lvl, opts)
+}
+
+// NewStdStreamLogger returns a Logger writing JSON entries below the error
+// level to stdout, and the others to stderr, as container platforms tell
+// application logs from errors by stream. The level is shared with the
+// package, see SetLogLevel.
+func NewStdStreamLogger(lvl zapcore.Level, opts ...Option) (*Logger, error) {
+	return newSplitLevelLogger("stdout", "stderr", zapcore.ErrorLevel, lvl, opts)
+}
+
+// newSplitLevelLogger returns a Logger writing the entries below split to
+// low, and the others to high.
+func newSplitLevelLogger(low, high string, split, lvl zapcore.Level, opts []Option) (*Logger, error) {
+	config := productionConfig(true, lvl)
+	config.OutputPaths = []string{low, high}
+	applyOptions(&config, opts)
+	return buildRoutedLogger(config, []route{
+		{paths: config.OutputPaths[:1], enab: zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l < split
+		})},
+		{paths: config.OutputPaths[1:], enab: zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= split
+		})},
+	})
+}
+
+// buildOptions returns the options every zap logger built by this package
+// from config is built with.
+func buildOptions(config zap.Config) []zap.Option {
+	enc := zapcore.NewJSONEncoder(config.EncoderConfig)
+	opts := []zap.Option{
+		zap.AddCallerSkip(1),
+		zap.WrapCore(newHooksCore),
+		zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return newByteCountingCore(core, enc)
+		}),
+		zap.WrapCore(newFieldMetricCore),
+		zap.WrapCore(newFieldProviderCore),
+	}
+	if !config.DisableStacktrace {
+		opts = append(opts, zap.AddStacktrace(zapcore.ErrorLevel))
 	}
-	return zl
+	return opts
+}
+
+// productionConfig returns the console config shared by the production
+// constructors, logging at the package level set to lvl.
+func productionConfig(jsonConsole bool, lvl zapcore.Level) zap.Config {
+	config := zap.NewProductionConfig()
+	if !jsonConsole {
+		config.OutputPaths = []string{"pretty://console"}
+	}
+	config.Level = level
+	level.SetLevel(lvl)
+	return config
+}
+
+// SetLogLevel changes the minimum level logged by the loggers built by this
+// package. It is safe to call while other goroutines are logging.
+func SetLogLevel(lvl zapcore.Level) {
+	level.SetLevel(lvl)
+}
+
+// GetLogLevel returns the minimum level logged by the loggers built by this
+// package.
+func GetLogLevel() zapcore.Level {
+	return level.Level()
+}
+
+// LogLevelHandler returns an http.Handler reporting the level shared by the
+// loggers of this package on GET, and changing it on PUT with a JSON body
+// such as {"level":"debug"}. Unknown levels are rejected with a 400.
+func LogLevelHandler() http.Handler {
+	return level
 }
 
 // Infow logs an info message and any additional given information.
 func Infow(msg string, keysAndValues ...interface{}) {
-	logger.Infow(msg, keysAndValues...)
-	infoLineCounter.Inc()
+	packageLogger().Infow(msg, keysAndValues...)
+}
+
+// Tracew logs a message and any additional given information at the trace
+// level.
+func Tracew(msg string, keysAndValues ...interface{}) {
+	packageLogger().Tracew(msg, keysAndValues...)
+}
+
+// Tracef formats and then logs the message at the trace level.
+func Tracef(format string, values ...interface{}) {
+	packageLogger().Tracef(format, values...)
+}
+
+// Trace logs a message at the trace level.
+func Trace(args ...interface{}) {
+	packageLogger().Trace(args...)
 }
 
 // Debugw logs a debug message and any additional given information.
 func Debugw(msg string, keysAndValues ...interface{}) {
-	logger.Debugw(msg, keysAndValues...)
-	debugLineCounter.Inc()
+	packageLogger().Debugw(msg, keysAndValues...)
 }
 
 // Warnw logs a debug message and any additional given information.
 func Warnw(msg string, keysAndValues ...interface{}) {
-	logger.Warnw(msg, keysAndValues...)
-	warnLineCounter.Inc()
+	packageLogger().Warnw(msg, keysAndValues...)
 }
 
 // Errorw logs an error message, any additional given information, and includes
-// stack trace.
+// stack trace unless the logger was built WithoutStacktraces.
 func Errorw(msg string, keysAndValues ...interface{}) {
-	logger.Errorw(msg, keysAndValues...)
-	errorLineCounter.Inc()
+	packageLogger().Errorw(msg, keysAndValues...)
 }
 
 // Infof formats and then logs the message.
 func Infof(format string, values ...interface{}) {
-	logger.Info(fmt.Sprintf(format, values...))
-	infoLineCounter.Inc()
+	packageLogger().Infof(format, values...)
 }
 
 // Debugf formats and then logs the message.
 func Debugf(format string, values ...interface{}) {
-	logger.Debug(fmt.Sprintf(format, values...))
-	debugLineCounter.Inc()
+	packageLogger().Debugf(format, values...)
 }
 
 // Warnf formats and then logs the message as Warn.
 func Warnf(format string, values ...interface{}) {
-	logger.Warn(fmt.Sprintf(format, values...))
-	warnLineCounter.Inc()
+	packageLogger().Warnf(format, values...)
 }
 
 // Panicf formats and then logs the message before panicking.
 func Panicf(format string, values ...interface{}) {
-	logger.Panic(fmt.Sprintf(format, values...))
-	panicLineCounter.Inc()
+	packageLogger().Panicf(format, values...)
+}
+
+// Panicw logs a panic message and any additional given information, then
+// panics.
+func Panicw(msg string, keysAndValues ...interface{}) {
+	packageLogger().Panicw(msg, keysAndValues...)
 }
 
 // Info logs an info message.
 func Info(args ...interface{}) {
-	logger.Info(args...)
-	infoLineCounter.Inc()
+	packageLogger().Info(args...)
 }
 
 // Debug logs a debug message.
 func Debug(args ...interface{}) {
-	logger.Debug(args...)
-	debugLineCounter.Inc()
+	packageLogger().Debug(args...)
 }
 
 // Warn logs a message at the warn level.
 func Warn(args ...interface{}) {
-	logger.Warn(args...)
-	warnLineCounter.Inc()
+	packageLogger().Warn(args...)
 }
 
 // Error logs an error message.
 func Error(args ...interface{}) {
-	logger.Error(args...)
-	errorLineCounter.Inc()
+	packageLogger().Error(args...)
 }
 
 // WarnIf logs the error if present.
 func WarnIf(err error) {
-	if err != nil {
-		logger.Warn(err)
-		warnLineCounter.Inc()
-	}
+	packageLogger().WarnIf(err)
 }
 
 // ErrorIf logs the error if present.
 func ErrorIf(err error, optionalMsg ...string) {
+	// Spare the hot success paths the read lock of packageLogger.
 	if err != nil {
-		if len(optionalMsg) > 0 {
-			logger.Error(errors.Wrap(err, optionalMsg[0]))
-		} else {
-			logger.Error(err)
-		}
-		errorLineCounter.Inc()
+		packageLogger().ErrorIf(err, optionalMsg...)
 	}
 }
 
-// ErrorIfCalling calls the given function and logs the error of it if there is.
-func ErrorIfCalling(f func() error, optionalMsg ...string) {
-	err := f()
+// ErrorIfError logs the error if present, like ErrorIf without a message.
+func ErrorIfError(err error) {
 	if err != nil {
-		e := errors.Wrap(err, runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name())
-		if len(optionalMsg) > 0 {
-			logger.Error(errors.Wrap(e, optionalMsg[0]))
-		} else {
-			logger.Error(e)
-		}
-		errorLineCounter.Inc()
+		packageLogger().ErrorIfError(err)
 	}
 }
 
-// PanicIf logs the error if present.
-func PanicIf(err error) {
-	if err != nil {
-		logger.Panic(err)
+// ErrorIfw logs msg with the error and any additional given information, if
+// the error is present.
+func ErrorIfw(err error, msg string, keysAndValues ...interface{}) {
+	packageLogger().ErrorIfw(err, msg, keysAndValues...)
+}
+
+// LogError logs msg with the error, if present, and returns the error.
+func LogError(err error, msg string) error {
+	if err == nil {
+		return nil
 	}
+	return packageLogger().LogError(err, msg)
+}
+
+// ErrorCoded logs the error, if present, with its code and any additional
+// given information, and counts it by code.
+func ErrorCoded(code string, err error, keysAndValues ...interface{}) {
+	packageLogger().ErrorCoded(code, err, keysAndValues...)
+}
+
+// ErrorIfCalling calls the given function and logs the error of it if there is.
+func ErrorIfCalling(f func() error, optionalMsg ...string) {
+	packageLogger().ErrorIfCalling(f, optionalMsg...)
+}
+
+// WarnIfCalling calls the given function and logs the error of it at the
+// warn level if there is.
+func WarnIfCalling(f func() error, optionalMsg ...string) {
+	packageLogger().WarnIfCalling(f, optionalMsg...)
+}
+
+// InfoIfCalling calls the given function and logs the error of it at the
+// info level if there is.
+func InfoIfCalling(f func() error, optionalMsg ...string) {
+	packageLogger().InfoIfCalling(f, optionalMsg...)
+}
+
+// PanicIf logs the error if present, wrapped with the optional message,
+// then panics.
+func PanicIf(err error, optionalMsg ...string) {
+	packageLogger().PanicIf(err, optionalMsg...)
+}
+
+// PanicIfw logs msg with the error and any additional given information, if
+// the error is present, then panics.
+func PanicIfw(err error, msg string, keysAndValues ...interface{}) {
+	packageLogger().PanicIfw(err, msg, keysAndValues...)
 }
 
 // Fatal logs a fatal message then exits the application.
 func Fatal(args ...interface{}) {
-	fatalLineCounter.Inc()
-	logger.Fatal(args...)
+	packageLogger().Fatal(args...)
 }
 
 // Errorf logs a message at the error level using Sprintf.
 func Errorf(format string, values ...interface{}) {
-	Error(fmt.Sprintf(format, values...))
-	errorLineCounter.Inc()
+	packageLogger().Errorf(format, values...)
 }
 
 // Fatalf logs a message at the fatal level using Sprintf.
 func Fatalf(format string, values ...interface{}) {
-	Fatal(fmt.Sprintf(format, values...))
-	fatalLineCounter.Inc()
+	packageLogger().Fatalf(format, values...)
+}
+
+// Fatalw logs a fatal message and any additional given information, then
+// exits the application.
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	packageLogger().Fatalw(msg, keysAndValues...)
 }
 
 // Panic logs a panic message then panics.
 func Panic(args ...interface{}) {
-	logger.Panic(args...)
-	panicLineCounter.Inc()
+	packageLogger().Panic(args...)
 }
 
-// Sync flushes any buffered log entries.
-func Sync() error {
-	return logger.Sync()
+// DPanic logs a message at the dpanic level, panicking if the logger is in
+// development mode.
+func DPanic(args ...interface{}) {
+	packageLogger().DPanic(args...)
 }
 
-var (
-	lineCounter = promauto.NewCounterVec(prometheus.CounterOpts{Name: "log_lines_total"}, []string{"level"})
-
-	debugLineCounter  = lineCounter.WithLabelValues(zapcore.DebugLevel.String())
-	infoLineCounter   = lineCounter.WithLabelValues(zapcore.InfoLevel.String())
-	warnLineCounter   = lineCounter.WithLabelValues(zapcore.WarnLevel.String())
-	errorLineCounter  = lineCounter.WithLabelValues(zapcore.ErrorLevel.String())
-	dPanicLineCounter = lineCounter.WithLabelValues(zapcore.DPanicLevel.String())
-	panicLineCounter  = lineCounter.WithLabelValues(zapcore.PanicLevel.String())
-	fatalLineCounter  = lineCounter.WithLabelValues(zapcore.FatalLevel.String())
-)
+// DPanicf formats and then logs the message at the dpanic level, panicking
+// if the logger is in development mode.
+func DPanicf(format string, values ...interface{}) {
+	packageLogger().DPanicf(format, values...)
+}
+
+// DPanicw logs a message and any additional given information at the dpanic
+// level, panicking if the logger is in development mode.
+func DPanicw(msg string, keysAndValues ...interface{}) {
+	packageLogger().DPanicw(msg, keysAndValues...)
+}
+
+// DebugEnabled reports whether the package logger writes entries at the
+// debug level.
+func DebugEnabled() bool {
+	return packageLogger().DebugEnabled()
+}
+
+// InfoEnabled reports whether the package logger writes entries at the info
+// level.
+func InfoEnabled() bool {
+	return packageLogger().InfoEnabled()
+}
+
+// WarnEnabled reports whether the package logger writes entries at the warn
+// level.
+func WarnEnabled() bool {
+	return packageLogger().WarnEnabled()
+}
+
+// ErrorEnabled reports whether the package logger writes entries at the
+// error level.
+func ErrorEnabled() bool {
+	return packageLogger().ErrorEnabled()
+}
+
+// Close flushes and releases the sinks of the package logger, see
+// Logger.Close, when it was set with ReplaceLogger or is the default one;
+// a logger set with SetLogger is only synced. It is meant to be called
+// once, at shutdown.
+func Close() error {
+	return GetLogger().Close()
+}
+
+// Sync flushes any buffered log entries, ignoring the errors of syncing
+// stdout and stderr, see Logger.Sync.
+func Sync() error {
+	return GetLogger().Sync()
+}
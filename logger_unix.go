@@ -2,10 +2,20 @@
 
 package logger
 
-import "path/filepath"
+import (
+	"net/url"
+	"path/filepath"
 
+	"go.uber.org/zap"
+)
+
+// registerOSSinks registers the syslog and journald sinks, see
+// newSyslogSink and newJournaldSink.
 func registerOSSinks() error {
-	return nil
+	if err := zap.RegisterSink("syslog", newSyslogSink); err != nil {
+		return err
+	}
+	return zap.RegisterSink("journald", newJournaldSink)
 }
 
 // logFileURI returns the full path to the file the
@@ -13,3 +23,8 @@ func registerOSSinks() error {
 func logFileURI(configRootDir string) string {
 	return filepath.ToSlash(filepath.Join(configRootDir, "log.jsonl"))
 }
+
+// filePathFromURI returns the file path of a file URI parsed by url.Parse.
+func filePathFromURI(u *url.URL) string {
+	return u.Path
+}
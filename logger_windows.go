@@ -18,12 +18,22 @@ func logFileURI(configRootDir string) string {
 	return "winfile:///" + filepath.ToSlash(filepath.Join(configRootDir, "log.jsonl"))
 }
 
+// registerOSSinks registers the winfile sink the log file is written with,
+// and the windows sink writing to the Windows Event Log, see newEventLogSink.
 func registerOSSinks() error {
-	return zap.RegisterSink("winfile", newWinFileSink)
+	if err := zap.RegisterSink("winfile", newWinFileSink); err != nil {
+		return err
+	}
+	return zap.RegisterSink("windows", newEventLogSink)
 }
 
 func newWinFileSink(u *url.URL) (zap.Sink, error) {
+	return os.OpenFile(filePathFromURI(u), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+}
+
+// filePathFromURI returns the file path of a file URI parsed by url.Parse.
+func filePathFromURI(u *url.URL) string {
 	// https://github.com/uber-go/zap/issues/621
 	// Remove leading slash left by url.Parse()
-	return os.OpenFile(u.Path[1:], os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	return u.Path[1:]
 }
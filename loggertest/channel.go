@@ -0,0 +1,53 @@
+package loggertest
+
+import (
+	"github.com/smartcontractkit/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// NewChannelLogger returns a Logger sending every entry, at all levels, with
+// its fields, to the returned channel of the given buffer size instead of
+// writing it out, for tests asserting on the order and timing of entries
+// logged over time.
+//
+// The channel applies backpressure: once its buffer is full, logging blocks
+// until the test receives an entry, so that none is dropped. Tests must keep
+// receiving while the code under test logs, or give a buffer large enough
+// for every entry it logs.
+func NewChannelLogger(buffer int) (*logger.Logger, <-chan observer.LoggedEntry) {
+	ch := make(chan observer.LoggedEntry, buffer)
+	return logger.NewLogger(zap.New(&channelCore{LevelEnabler: zapcore.DebugLevel, ch: ch})), ch
+}
+
+// channelCore sends the entries it writes to a channel.
+type channelCore struct {
+	zapcore.LevelEnabler
+	// context holds the fields added with With.
+	context []zapcore.Field
+	ch      chan<- observer.LoggedEntry
+}
+
+func (c *channelCore) With(fields []zapcore.Field) zapcore.Core {
+	child := *c
+	child.context = append(c.context[:len(c.context):len(c.context)], fields...)
+	return &child
+}
+
+func (c *channelCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *channelCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	all := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	c.ch <- observer.LoggedEntry{Entry: entry, Context: append(append(all, c.context...), fields...)}
+	return nil
+}
+
+func (c *channelCore) Sync() error {
+	return nil
+}
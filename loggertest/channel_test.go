@@ -0,0 +1,50 @@
+package loggertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewChannelLogger(t *testing.T) {
+	l, entries := NewChannelLogger(1)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.With("job", "sync").Debugw("started")
+		l.Warnw("finished", "key", "value")
+	}()
+
+	first := <-entries
+	assert.Equal(t, zapcore.DebugLevel, first.Level)
+	assert.Equal(t, "started", first.Message)
+	assert.Equal(t, map[string]interface{}{"job": "sync"}, first.ContextMap())
+
+	second := <-entries
+	assert.Equal(t, zapcore.WarnLevel, second.Level)
+	assert.Equal(t, "finished", second.Message)
+	assert.Equal(t, map[string]interface{}{"key": "value"}, second.ContextMap())
+	assert.False(t, second.Time.Before(first.Time))
+	<-done
+}
+
+func TestNewChannelLogger_Blocks(t *testing.T) {
+	l, entries := NewChannelLogger(0)
+
+	logged := make(chan struct{})
+	go func() {
+		l.Infow("blocked")
+		close(logged)
+	}()
+
+	select {
+	case <-logged:
+		t.Fatal("logging did not block on the full channel")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, "blocked", (<-entries).Message)
+	<-logged
+}
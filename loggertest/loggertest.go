@@ -0,0 +1,17 @@
+// Package loggertest provides helpers for asserting on the entries logged
+// through a logger.Logger in tests.
+package loggertest
+
+import (
+	"github.com/smartcontractkit/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// NewTestLogger returns a Logger recording every entry, at all levels, in
+// the returned ObservedLogs instead of writing it out.
+func NewTestLogger() (*logger.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return logger.NewLogger(zap.New(core)), logs
+}
@@ -0,0 +1,30 @@
+package loggertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewTestLogger(t *testing.T) {
+	l, logs := NewTestLogger()
+
+	traceID := trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID})
+	span := trace.SpanFromContext(trace.ContextWithSpanContext(context.Background(), sc))
+
+	l.Debugw("debug", "key", "value")
+	l.WithSpan(span).Errorw("error")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, zapcore.DebugLevel, entries[0].Level)
+	assert.Equal(t, "debug", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{"key": "value"}, entries[0].ContextMap())
+	assert.Equal(t, zapcore.ErrorLevel, entries[1].Level)
+	assert.Equal(t, traceID.String(), entries[1].ContextMap()["TraceID"])
+}
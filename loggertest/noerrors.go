@@ -0,0 +1,77 @@
+package loggertest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/smartcontractkit/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	// registerHook registers recordErrors, the one hook the assertions of
+	// AssertNoErrors share, as hooks can't be unregistered.
+	registerHook sync.Once
+
+	recordersMu sync.Mutex
+	recorders   = map[*errorRecorder]struct{}{}
+)
+
+// AssertNoErrors fails t, once it has completed, if any entry was logged
+// at the error level or above in the meantime, for tests to catch errors
+// logged unexpectedly. It observes the entries of every logger built by
+// the logger package, the package logger included, whichever goroutine logs
+// them, so the entries of the tests running in parallel with t fail it too.
+// The loggers of NewTestLogger, built from a core of their own, are not
+// observed: assert on their ObservedLogs instead.
+func AssertNoErrors(t testing.TB) {
+	t.Helper()
+	registerHook.Do(func() {
+		logger.RegisterHook(recordErrors)
+	})
+
+	r := &errorRecorder{}
+	recordersMu.Lock()
+	recorders[r] = struct{}{}
+	recordersMu.Unlock()
+
+	t.Cleanup(func() {
+		recordersMu.Lock()
+		delete(recorders, r)
+		recordersMu.Unlock()
+
+		for _, entry := range r.entries() {
+			t.Errorf("unexpected %s entry logged from %s: %s", entry.Level, entry.Caller.TrimmedPath(), entry.Message)
+		}
+	})
+}
+
+// errorRecorder records the entries logged at the error level and above.
+type errorRecorder struct {
+	mu     sync.Mutex
+	logged []zapcore.Entry
+}
+
+func (r *errorRecorder) record(entry zapcore.Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logged = append(r.logged, entry)
+}
+
+func (r *errorRecorder) entries() []zapcore.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]zapcore.Entry(nil), r.logged...)
+}
+
+func recordErrors(entry zapcore.Entry) error {
+	if entry.Level < zapcore.ErrorLevel {
+		return nil
+	}
+	recordersMu.Lock()
+	defer recordersMu.Unlock()
+	for r := range recorders {
+		r.record(entry)
+	}
+	return nil
+}
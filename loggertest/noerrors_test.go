@@ -0,0 +1,58 @@
+package loggertest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/smartcontractkit/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+// fakeTB records the failures and cleanups of a test.
+type fakeTB struct {
+	testing.TB
+	cleanups []func()
+	errors   []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Cleanup(f func()) { tb.cleanups = append(tb.cleanups, f) }
+
+func (tb *fakeTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func (tb *fakeTB) cleanup() {
+	for i := len(tb.cleanups) - 1; i >= 0; i-- {
+		tb.cleanups[i]()
+	}
+}
+
+func TestAssertNoErrors(t *testing.T) {
+	l := logger.NewWriterLogger(ioutil.Discard, zapcore.DebugLevel, true)
+
+	t.Run("no errors", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		AssertNoErrors(tb)
+		l.Infow("fine")
+		l.Warnw("still fine")
+		tb.cleanup()
+		assert.Empty(t, tb.errors)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		AssertNoErrors(tb)
+		l.Errorw("went wrong")
+		tb.cleanup()
+		l.Errorw("after the test")
+
+		if assert.Len(t, tb.errors, 1) {
+			assert.Contains(t, tb.errors[0], "unexpected error entry logged from loggertest/noerrors_test.go:")
+			assert.Contains(t, tb.errors[0], "went wrong")
+		}
+	})
+}
@@ -0,0 +1,334 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+// counters are the counters of the package, which ConfigureMetrics replaces
+// all at once.
+type counters struct {
+	lines *prometheus.CounterVec
+	// traceLines to fatalLines are the counters of lines for each level.
+	traceLines, debugLines, infoLines, warnLines, errorLines,
+	dPanicLines, panicLines, fatalLines prometheus.Counter
+
+	bytes        *prometheus.CounterVec
+	droppedLines *prometheus.CounterVec
+	writeErrors  *prometheus.CounterVec
+	auditEvents  *prometheus.CounterVec
+	errorCodes   *prometheus.CounterVec
+}
+
+// newCounters returns the counters of the package, named with the given
+// namespace and subsystem.
+func newCounters(namespace, subsystem string) *counters {
+	c := &counters{
+		bytes:        newCounterVec(namespace, subsystem, "log_bytes_total"),
+		droppedLines: newCounterVec(namespace, subsystem, "log_lines_dropped_total"),
+		writeErrors:  newCounterVec(namespace, subsystem, "log_write_errors_total"),
+		auditEvents:  newAuditEventCounter(namespace, subsystem),
+		errorCodes:   newErrorCodeCounter(namespace, subsystem),
+	}
+	c.setLines(newCounterVec(namespace, subsystem, "log_lines_total"))
+	return c
+}
+
+// setLines sets the counter of lines of c, and those of each level.
+func (c *counters) setLines(lines *prometheus.CounterVec) {
+	c.lines = lines
+	c.traceLines = lines.WithLabelValues(levelName(TraceLevel))
+	c.debugLines = lines.WithLabelValues(zapcore.DebugLevel.String())
+	c.infoLines = lines.WithLabelValues(zapcore.InfoLevel.String())
+	c.warnLines = lines.WithLabelValues(zapcore.WarnLevel.String())
+	c.errorLines = lines.WithLabelValues(zapcore.ErrorLevel.String())
+	c.dPanicLines = lines.WithLabelValues(zapcore.DPanicLevel.String())
+	c.panicLines = lines.WithLabelValues(zapcore.PanicLevel.String())
+	c.fatalLines = lines.WithLabelValues(zapcore.FatalLevel.String())
+}
+
+// vecs returns the counter vectors of c.
+func (c *counters) vecs() []*prometheus.CounterVec {
+	return []*prometheus.CounterVec{c.lines, c.bytes, c.droppedLines, c.writeErrors, c.auditEvents, c.errorCodes}
+}
+
+// currentCounters holds the *counters of the package, loaded by every line
+// while ConfigureMetrics may replace them. It is set with the variables of
+// the package, as the init functions of the package already log.
+var currentCounters = newCountersValue(registeredCounters())
+
+func newCountersValue(c *counters) *atomic.Value {
+	v := &atomic.Value{}
+	v.Store(c)
+	return v
+}
+
+// getCounters returns the current counters of the package.
+func getCounters() *counters {
+	return currentCounters.Load().(*counters)
+}
+
+// newCounterVec returns a counter of lines or bytes, by level.
+func newCounterVec(namespace, subsystem, name string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      name,
+	}, []string{"level"})
+}
+
+// newAuditEventCounter returns the counter of the events logged by Audit, by
+// event.
+func newAuditEventCounter(namespace, subsystem string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "log_audit_events_total",
+	}, []string{"event"})
+}
+
+// newErrorCodeCounter returns the counter of the errors logged by
+// ErrorCoded, by code.
+func newErrorCodeCounter(namespace, subsystem string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "log_error_codes_total",
+	}, []string{"code"})
+}
+
+var (
+	// metricsMu guards metricsRegisterer, and the namespace and subsystem
+	// of the metrics.
+	metricsMu sync.Mutex
+	// metricsRegisterer is the registerer the collectors are registered
+	// with, nil when they are not registered at all.
+	metricsRegisterer prometheus.Registerer = prometheus.DefaultRegisterer
+	// metricsNamespace and metricsSubsystem are those set by
+	// ConfigureMetrics.
+	metricsNamespace, metricsSubsystem string
+)
+
+// registeredCounters returns the counters of the package, registered with
+// the default registry, or those already registered there under the same
+// names by another copy of the package, as vendored by another module of the
+// binary. Only counters conflicting with them, if the copies disagree on the
+// labels, panic.
+func registeredCounters() *counters {
+	c := newCounters("", "")
+	for _, v := range []**prometheus.CounterVec{&c.lines, &c.bytes, &c.droppedLines, &c.writeErrors, &c.auditEvents, &c.errorCodes} {
+		registered, err := registerCounterVec(metricsRegisterer, *v)
+		if err != nil {
+			panic(err)
+		}
+		*v = registered
+	}
+	c.setLines(c.lines)
+	return c
+}
+
+// registerCounterVec registers c with r, and returns it, unless an identical
+// counter is already registered, which is returned instead.
+func registerCounterVec(r prometheus.Registerer, c *prometheus.CounterVec) (*prometheus.CounterVec, error) {
+	if err := r.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if existing, ok := are.ExistingCollector.(*prometheus.CounterVec); ok {
+				return existing, nil
+			}
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// collectors returns the Prometheus collectors of the package, including the
+// counters of the tracked fields.
+func collectors() []prometheus.Collector {
+	var cs []prometheus.Collector
+	for _, c := range getCounters().vecs() {
+		cs = append(cs, c)
+	}
+	for _, t := range getTrackedFields() {
+		cs = append(cs, t.counter)
+	}
+	return cs
+}
+
+// SetMetricsRegisterer registers the package's metrics with r instead of the
+// registerer they are currently registered with, the default Prometheus
+// registry unless changed. A nil r leaves the metrics unregistered.
+func SetMetricsRegisterer(r prometheus.Registerer) error {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	if r != nil {
+		for i, c := range collectors() {
+			if err := r.Register(c); err != nil {
+				for _, registered := range collectors()[:i] {
+					r.Unregister(registered)
+				}
+				return err
+			}
+		}
+	}
+	if metricsRegisterer != nil {
+		for _, c := range collectors() {
+			metricsRegisterer.Unregister(c)
+		}
+	}
+	metricsRegisterer = r
+	return nil
+}
+
+// ConfigureMetrics prefixes the names of the package's metrics with the
+// given Prometheus namespace and subsystem, either of which may be empty,
+// so that with the chainlink namespace log_lines_total is exported as
+// chainlink_log_lines_total. The metrics are replaced by new ones, counting
+// from zero, in the registerer they are registered with.
+//
+// It is safe to call while other goroutines are logging, but the lines they
+// log meanwhile may be counted by the metrics being replaced.
+func ConfigureMetrics(namespace, subsystem string) error {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	c := newCounters(namespace, subsystem)
+	tracked := getTrackedFields()
+	retracked := make([]*trackedField, len(tracked))
+	for i, t := range tracked {
+		retracked[i] = &trackedField{key: t.key, counter: newFieldCounter(namespace, subsystem, t.key), values: t.values}
+	}
+	if metricsRegisterer != nil {
+		for _, c := range collectors() {
+			metricsRegisterer.Unregister(c)
+		}
+		var replacements []prometheus.Collector
+		for _, v := range c.vecs() {
+			replacements = append(replacements, v)
+		}
+		for _, t := range retracked {
+			replacements = append(replacements, t.counter)
+		}
+		for i, c := range replacements {
+			if err := metricsRegisterer.Register(c); err != nil {
+				for _, registered := range replacements[:i] {
+					metricsRegisterer.Unregister(registered)
+				}
+				for _, c := range collectors() {
+					_ = metricsRegisterer.Register(c)
+				}
+				return err
+			}
+		}
+	}
+
+	currentCounters.Store(c)
+	setTrackedFields(retracked)
+	metricsNamespace, metricsSubsystem = namespace, subsystem
+	return nil
+}
+
+// byteCountingCore adds the size of every entry written by the wrapped core,
+// encoded with its fields by enc, to the log_bytes_total counter.
+type byteCountingCore struct {
+	zapcore.Core
+	enc zapcore.Encoder
+}
+
+func newByteCountingCore(core zapcore.Core, enc zapcore.Encoder) zapcore.Core {
+	return &byteCountingCore{Core: core, enc: enc}
+}
+
+func (c *byteCountingCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &byteCountingCore{Core: c.Core.With(fields), enc: enc}
+}
+
+func (c *byteCountingCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	// Only count the entries the wrapped core, which may be sampling, writes.
+	if downstream, ok := checkDownstream(c.Core, entry, ce); ok {
+		return downstream.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write only measures the entry, the wrapped core was added to the checked
+// entry by Check and writes it itself.
+func (c *byteCountingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	getCounters().bytes.WithLabelValues(levelName(entry.Level)).Add(float64(buf.Len()))
+	buf.Free()
+	return nil
+}
+
+// writeErrorCountingCore counts the entries the wrapped core, writing to its
+// outputs, fails to write, in the log_write_errors_total counter. The errors
+// are still reported to the error outputs of the logger.
+type writeErrorCountingCore struct {
+	zapcore.Core
+}
+
+func newWriteErrorCountingCore(core zapcore.Core) zapcore.Core {
+	return &writeErrorCountingCore{Core: core}
+}
+
+func (c *writeErrorCountingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &writeErrorCountingCore{Core: c.Core.With(fields)}
+}
+
+func (c *writeErrorCountingCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *writeErrorCountingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(entry, fields)
+	if err != nil {
+		getCounters().writeErrors.WithLabelValues(levelName(entry.Level)).Inc()
+	}
+	return err
+}
+
+// otherLabelValue is the label value the values past the maximum of a
+// boundedLabelValues are counted under.
+const otherLabelValue = "_other"
+
+// boundedLabelValues bounds the number of distinct values of a label, such
+// as those taken from the fields of the entries, to bound the number of
+// series of its counter.
+type boundedLabelValues struct {
+	max int
+
+	mu sync.Mutex
+	// values are the distinct values counted under their own label value.
+	values map[string]bool
+}
+
+func newBoundedLabelValues(max int) *boundedLabelValues {
+	return &boundedLabelValues{max: max, values: map[string]bool{}}
+}
+
+// label returns value, or otherLabelValue if max other values were seen
+// before it.
+func (b *boundedLabelValues) label(value string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.values[value] {
+		if len(b.values) >= b.max {
+			return otherLabelValue
+		}
+		b.values[value] = true
+	}
+	return value
+}
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSetMetricsRegisterer(t *testing.T) {
+	defer func() { require.NoError(t, SetMetricsRegisterer(prometheus.DefaultRegisterer)) }()
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, SetMetricsRegisterer(registry))
+	Info("registered")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var names []string
+	for _, f := range families {
+		names = append(names, f.GetName())
+	}
+	assert.Subset(t, names, []string{"log_bytes_total", "log_lines_total"})
+	assert.False(t, prometheus.DefaultRegisterer.Unregister(getCounters().lines), "should no longer be registered by default")
+
+	// Registering with a registry that already has the collectors fails
+	// and leaves the current registration untouched.
+	require.NoError(t, SetMetricsRegisterer(nil))
+	other := prometheus.NewRegistry()
+	require.NoError(t, other.Register(getCounters().lines))
+	assert.Error(t, SetMetricsRegisterer(other))
+	assert.True(t, other.Unregister(getCounters().lines))
+}
+
+func TestRegisterCounterVec(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	vendored := newCounterVec("", "", "log_lines_total")
+	registered, err := registerCounterVec(registry, vendored)
+	require.NoError(t, err)
+	assert.Same(t, vendored, registered)
+
+	// Another copy of the package, vendored by another module.
+	registered, err = registerCounterVec(registry, newCounterVec("", "", "log_lines_total"))
+	require.NoError(t, err)
+	assert.Same(t, vendored, registered)
+
+	conflicting := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "log_lines_total"}, []string{"severity"})
+	_, err = registerCounterVec(registry, conflicting)
+	assert.Error(t, err)
+}
+
+func TestByteCountingCore(t *testing.T) {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	observed, _ := observer.New(zapcore.InfoLevel)
+	core := newByteCountingCore(observed, zapcore.NewJSONEncoder(encoderConfig))
+	l := NewLogger(zap.New(core))
+
+	before := testutil.ToFloat64(getCounters().bytes.WithLabelValues("warn"))
+	l.With("TraceID", "abc").Warnw("counted", "key", "value")
+
+	after := testutil.ToFloat64(getCounters().bytes.WithLabelValues("warn"))
+	// {"level":"warn","ts":...,"msg":"counted","TraceID":"abc","key":"value"}
+	assert.InDelta(t, 90, after-before, 10)
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteErrorCountingCore(t *testing.T) {
+	l := NewWriterLogger(failingWriter{}, zapcore.InfoLevel, true)
+	errorOutput := &bytes.Buffer{}
+	l = l.child(l.Desugar().WithOptions(zap.ErrorOutput(zapcore.AddSync(errorOutput))).Sugar())
+
+	before := testutil.ToFloat64(getCounters().writeErrors.WithLabelValues("warn"))
+	l.Warn("lost")
+	l.Debug("disabled")
+	assert.Equal(t, before+1, testutil.ToFloat64(getCounters().writeErrors.WithLabelValues("warn")))
+	assert.Contains(t, errorOutput.String(), "disk full", "errors are still reported")
+}
+
+func TestConfigureMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, SetMetricsRegisterer(registry))
+	defer func() {
+		require.NoError(t, ConfigureMetrics("", ""))
+		require.NoError(t, SetMetricsRegisterer(prometheus.DefaultRegisterer))
+	}()
+
+	require.NoError(t, ConfigureMetrics("chainlink", ""))
+	Info("namespaced")
+	assert.Equal(t, 1.0, testutil.ToFloat64(getCounters().infoLines))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var names []string
+	for _, f := range families {
+		names = append(names, f.GetName())
+	}
+	assert.Contains(t, names, "chainlink_log_lines_total")
+	assert.NotContains(t, names, "log_lines_total")
+
+	require.NoError(t, ConfigureMetrics("chainlink", "node"))
+	Warn("subsystem")
+	families, err = registry.Gather()
+	require.NoError(t, err)
+	names = nil
+	for _, f := range families {
+		names = append(names, f.GetName())
+	}
+	assert.Contains(t, names, "chainlink_node_log_lines_total")
+	assert.NotContains(t, names, "chainlink_log_lines_total")
+}
+
+func TestConfigureMetrics_Concurrent(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, SetMetricsRegisterer(registry))
+	defer func() {
+		require.NoError(t, ConfigureMetrics("", ""))
+		require.NoError(t, SetMetricsRegisterer(prometheus.DefaultRegisterer))
+	}()
+
+	l := NewWriterLogger(ioutil.Discard, zapcore.DebugLevel, true)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					l.Infow("concurrent")
+				}
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		require.NoError(t, ConfigureMetrics("chainlink", ""))
+		require.NoError(t, ConfigureMetrics("", ""))
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestBoundedLabelValues(t *testing.T) {
+	b := newBoundedLabelValues(2)
+	assert.Equal(t, "a", b.label("a"))
+	assert.Equal(t, "b", b.label("b"))
+	assert.Equal(t, otherLabelValue, b.label("c"))
+	assert.Equal(t, "a", b.label("a"), "values seen before the maximum keep their label")
+}
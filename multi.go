@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewMultiLogger returns a Logger writing every entry to all the given
+// loggers, through their cores composed with zapcore.NewTee, so that each
+// keeps its own level, encoding, outputs and fields added with With. Sync
+// syncs them all and combines their errors, and Close closes them all.
+//
+// Lines are counted once by the returned Logger, as are the tracked fields
+// of TrackFieldAsMetric, while log_bytes_total counts the bytes written by
+// each of the loggers. The hooks of RegisterHook and RegisterFatalHook run
+// once, after every logger wrote the entry. The entries are named, and get
+// a caller and stacktrace, as the first of the loggers names them and adds
+// them, and the errors of the returned Logger are reported to its error
+// output.
+func NewMultiLogger(loggers ...*Logger) *Logger {
+	if len(loggers) == 0 {
+		return NewNopLogger()
+	}
+	cores := make([]zapcore.Core, len(loggers))
+	for i, l := range loggers {
+		cores[i] = unhooked(l.Desugar().Core())
+	}
+	first := loggers[0]
+	l := NewLogger(first.Desugar().WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return newFieldProviderCore(newFieldMetricCore(newHooksCore(zapcore.NewTee(cores...))))
+	})))
+	l.name = first.name
+	l.errorOutput = first.errorOutput
+	l.closer = &closer{close: func() {
+		for _, wrapped := range loggers {
+			// Already synced by Close.
+			_ = wrapped.Close()
+		}
+	}}
+	return l
+}
+
+// unhooked returns core without the cores of the package that must act once
+// per entry, the hooks and the field providers and metrics, which
+// NewMultiLogger wraps the tee of the loggers with instead. The levels of
+// the logger, its sampling and the counting of its bytes are kept.
+func unhooked(core zapcore.Core) zapcore.Core {
+	switch c := core.(type) {
+	case *namedLevelCore:
+		inner := *c
+		inner.Core = unhooked(c.Core)
+		return &inner
+	case *byteCountingCore:
+		return &byteCountingCore{Core: unhooked(c.Core), enc: c.enc}
+	case *hooksCore:
+		return unhooked(c.Core)
+	case *fieldMetricCore:
+		return unhooked(c.Core)
+	case *fieldProviderCore:
+		return unhooked(c.Core)
+	case *traceUnsampledCore:
+		// The zap sampler can't be unwrapped, it is built again.
+		inner := unhooked(c.unsampled)
+		return &traceUnsampledCore{Core: c.sample(inner), unsampled: inner, sample: c.sample}
+	case *levelSampledCore:
+		samplers := make(map[zapcore.Level]zapcore.Core, len(c.samplers))
+		for lvl, s := range c.samplers {
+			samplers[lvl] = unhooked(s)
+		}
+		return &levelSampledCore{Core: unhooked(c.Core), samplers: samplers}
+	case *keySampledCore:
+		return &keySampledCore{Core: unhooked(c.Core), state: c.state, value: c.value}
+	case *dedupCore:
+		return &dedupCore{Core: unhooked(c.Core), state: c.state}
+	case *rateLimitedCore:
+		return &rateLimitedCore{Core: unhooked(c.Core), limiter: c.limiter}
+	}
+	return core
+}
+
+// checkDownstream checks entry against core, reporting whether core writes
+// it, for the cores acting on the entries their wrapped core writes only.
+// Within a zapcore.NewTee, ce already holds the cores of the previous
+// branches, so that whether core added any can't be told from ce: core is
+// then checked apart, and written through a checkedCore.
+func checkDownstream(core zapcore.Core, entry zapcore.Entry, ce *zapcore.CheckedEntry) (*zapcore.CheckedEntry, bool) {
+	if ce == nil {
+		downstream := core.Check(entry, nil)
+		return downstream, downstream != nil
+	}
+	checked := core.Check(entry, nil)
+	if checked == nil {
+		return ce, false
+	}
+	return ce.AddCore(entry, &checkedCore{Core: core, checked: checked, outer: ce}), true
+}
+
+// checkedCore writes an entry checked apart from the checked entry outer it
+// was added to.
+type checkedCore struct {
+	zapcore.Core
+	checked *zapcore.CheckedEntry
+	outer   *zapcore.CheckedEntry
+}
+
+// Write writes the entry to the cores that checked it, which report their
+// errors to the error output of outer. Both the entry, to which the logger
+// adds the caller and stack once checked, and the error output are taken
+// from outer.
+func (c *checkedCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	c.checked.Entry = entry
+	c.checked.ErrorOutput = c.outer.ErrorOutput
+	c.checked.Write(fields...)
+	return nil
+}
@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// failingSyncer is a bytes.Buffer failing to be synced.
+type failingSyncer struct {
+	bytes.Buffer
+	err error
+}
+
+func (s *failingSyncer) Sync() error { return s.err }
+
+func TestNewMultiLogger(t *testing.T) {
+	var primary, audit bytes.Buffer
+	l := NewMultiLogger(
+		NewWriterLogger(&primary, zapcore.DebugLevel, false),
+		NewWriterLogger(&audit, zapcore.InfoLevel, true).With("audit", true),
+	)
+
+	before := testutil.ToFloat64(getCounters().infoLines)
+	l.Debugw("primary only")
+	l.Named("payments").Infow("both", "key", "value")
+	require.NoError(t, l.Sync())
+	assert.Equal(t, before+1, testutil.ToFloat64(getCounters().infoLines))
+
+	assert.Contains(t, primary.String(), "primary only")
+	assert.Contains(t, primary.String(), "[INFO]")
+	assert.Contains(t, primary.String(), "multi_test.go")
+	assert.NotContains(t, audit.String(), "primary only")
+	assert.Contains(t, audit.String(), `"logger":"payments"`)
+	assert.Contains(t, audit.String(), `"msg":"both","audit":true,"key":"value"`)
+}
+
+func TestNewMultiLogger_Sync(t *testing.T) {
+	first := &failingSyncer{err: errors.New("first failed")}
+	second := &failingSyncer{err: errors.New("second failed")}
+	l := NewMultiLogger(
+		NewWriterLogger(first, zapcore.InfoLevel, true),
+		NewWriterLogger(second, zapcore.InfoLevel, true),
+	)
+
+	err := l.Sync()
+	assert.ElementsMatch(t, []error{first.err, second.err}, multierr.Errors(err))
+	assert.Equal(t, err, l.Close())
+	// Closed once.
+	assert.Equal(t, err, l.Close())
+}
+
+func TestNewMultiLogger_HooksRunOnce(t *testing.T) {
+	defer func() { entryHooks, fatalHooks = nil, nil }()
+	var entries, fatals int
+	RegisterHook(func(zapcore.Entry) error {
+		entries++
+		return nil
+	})
+	RegisterFatalHook(func() { fatals++ })
+
+	var first, second bytes.Buffer
+	l := NewMultiLogger(
+		NewWriterLogger(&first, zapcore.InfoLevel, true),
+		NewWriterLogger(&second, zapcore.InfoLevel, true),
+	)
+	l.Infow("hooked")
+	assert.Equal(t, 1, entries)
+
+	// Written through the core, as the logger would exit.
+	ce := l.Desugar().Core().Check(zapcore.Entry{Level: zapcore.FatalLevel, Message: "fatal"}, nil)
+	require.NotNil(t, ce)
+	ce.Write()
+	assert.Equal(t, 2, entries)
+	assert.Equal(t, 1, fatals)
+	assert.Contains(t, first.String(), `"msg":"fatal"`)
+	assert.Contains(t, second.String(), `"msg":"fatal"`)
+}
+
+func TestNewMultiLogger_SampledHooksRunOnce(t *testing.T) {
+	defer func() { entryHooks = nil }()
+	var entries int
+	RegisterHook(func(zapcore.Entry) error {
+		entries++
+		return nil
+	})
+
+	for name, wrap := range map[string]func(*Logger) *Logger{
+		"NewSampledLogger": func(l *Logger) *Logger { return NewSampledLogger(l, 1, 1) },
+		"NewLevelSampledLogger": func(l *Logger) *Logger {
+			return NewLevelSampledLogger(l, map[zapcore.Level]SamplingPolicy{zapcore.InfoLevel: {Initial: 1}})
+		},
+		"NewKeySampledLogger":  func(l *Logger) *Logger { return NewKeySampledLogger(l, "sampling_key", 1, 1) },
+		"NewDedupLogger":       func(l *Logger) *Logger { return NewDedupLogger(l, time.Hour) },
+		"NewRateLimitedLogger": func(l *Logger) *Logger { return NewRateLimitedLogger(l, time.Hour) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			entries = 0
+			var first, second bytes.Buffer
+			l := NewMultiLogger(
+				wrap(NewWriterLogger(&first, zapcore.InfoLevel, true)),
+				NewWriterLogger(&second, zapcore.InfoLevel, true),
+			)
+			l.Infow("hooked")
+			assert.Equal(t, 1, entries)
+			assert.Contains(t, first.String(), `"msg":"hooked"`)
+			assert.Contains(t, second.String(), `"msg":"hooked"`)
+		})
+	}
+}
+
+func TestNewMultiLogger_InheritsOptions(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := NewMultiLogger(NewLogger(zap.New(core)))
+	l.Errorw("no caller")
+	require.Equal(t, 1, logs.Len())
+	assert.False(t, logs.All()[0].Caller.Defined)
+	assert.Empty(t, logs.All()[0].Stack)
+
+	var buf bytes.Buffer
+	l = NewMultiLogger(NewWriterLogger(&buf, zapcore.InfoLevel, true).Named("txmanager"), NewLogger(zap.New(core)))
+	l.Errorw("caller")
+	assert.Contains(t, buf.String(), `"logger":"txmanager"`)
+	assert.Contains(t, buf.String(), "multi_test.go")
+	assert.Contains(t, buf.String(), `"stacktrace":`)
+}
+
+func TestNewMultiLogger_CountsWrittenBytesOnly(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sampled.jsonl")
+
+	config := productionConfig(true, zapcore.InfoLevel)
+	config.OutputPaths = []string{path}
+	config.Sampling = &zap.SamplingConfig{Initial: 1, Thereafter: 1000}
+	sampled, err := buildLogger(config)
+	require.NoError(t, err)
+	var primary bytes.Buffer
+	l := NewMultiLogger(NewWriterLogger(&primary, zapcore.InfoLevel, true), sampled)
+
+	before := testutil.ToFloat64(getCounters().bytes.WithLabelValues("info"))
+	for i := 0; i < 3; i++ {
+		l.Infow("repeated", "key", "value")
+	}
+	require.NoError(t, l.Close())
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(b), "repeated"))
+	assert.Contains(t, string(b), "multi_test.go", "the entries keep their caller")
+	assert.Equal(t, before+float64(primary.Len()+len(b)), testutil.ToFloat64(getCounters().bytes.WithLabelValues("info")),
+		"the bytes of the entries sampled out should not be counted")
+}
@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	// namedLevelsMu serializes the updates of namedLevels.
+	namedLevelsMu sync.Mutex
+	// namedLevels holds the map[string]zap.AtomicLevel of the level
+	// overrides by logger name. It is replaced, rather than updated, when
+	// a name is added or removed, so that it can be read without locking.
+	namedLevels atomic.Value
+)
+
+func init() {
+	namedLevels.Store(map[string]zap.AtomicLevel{})
+}
+
+// SetNamedLevel overrides the level of the loggers with the given name, as
+// set by Named, and of their descendants, of which "txmanager.broadcaster"
+// is one for "txmanager". The loggers of the other names keep logging at
+// the level of the logger they were named from, such as the one set with
+// SetLogLevel. Overrides apply to the loggers built by this package.
+func SetNamedLevel(name string, lvl zapcore.Level) {
+	namedLevelsMu.Lock()
+	defer namedLevelsMu.Unlock()
+
+	levels := namedLevels.Load().(map[string]zap.AtomicLevel)
+	if atomicLevel, ok := levels[name]; ok {
+		atomicLevel.SetLevel(lvl)
+		return
+	}
+	updated := make(map[string]zap.AtomicLevel, len(levels)+1)
+	for n, l := range levels {
+		updated[n] = l
+	}
+	updated[name] = zap.NewAtomicLevelAt(lvl)
+	namedLevels.Store(updated)
+}
+
+// ClearNamedLevel removes the level override of the given name, if any.
+func ClearNamedLevel(name string) {
+	namedLevelsMu.Lock()
+	defer namedLevelsMu.Unlock()
+
+	levels := namedLevels.Load().(map[string]zap.AtomicLevel)
+	if _, ok := levels[name]; !ok {
+		return
+	}
+	updated := make(map[string]zap.AtomicLevel, len(levels))
+	for n, l := range levels {
+		if n != name {
+			updated[n] = l
+		}
+	}
+	namedLevels.Store(updated)
+}
+
+// namedLevel returns the level override of name, or else of the closest of
+// its ancestors.
+func namedLevel(name string) (zapcore.Level, bool) {
+	levels := namedLevels.Load().(map[string]zap.AtomicLevel)
+	if len(levels) == 0 {
+		return 0, false
+	}
+	for {
+		if l, ok := levels[name]; ok {
+			return l.Level(), true
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			return 0, false
+		}
+		name = name[:i]
+	}
+}
+
+// namedLevelEnabler enables the levels of base, and those of the level
+// overrides, for entries logged under the overridden names only.
+type namedLevelEnabler struct {
+	base zapcore.LevelEnabler
+}
+
+// Enabled reports whether lvl is enabled by base, by any override, or by a
+// running AtLevel call, as the cores below a namedLevelCore must let through
+// every entry that may be written.
+func (e namedLevelEnabler) Enabled(lvl zapcore.Level) bool {
+	if e.base.Enabled(lvl) || forcedEnabled(lvl) {
+		return true
+	}
+	for _, l := range namedLevels.Load().(map[string]zap.AtomicLevel) {
+		if l.Enabled(lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+// enabledFor reports whether entries at lvl are written for loggers with
+// the given name.
+func (e namedLevelEnabler) enabledFor(name string, lvl zapcore.Level) bool {
+	if override, ok := namedLevel(name); ok {
+		return override.Enabled(lvl)
+	}
+	return e.base.Enabled(lvl)
+}
+
+// namedLevelCore drops the entries whose logger name is not enabled at
+// their level, unless forced, by AtLevel, to write those at forcedLevel and
+// above.
+type namedLevelCore struct {
+	zapcore.Core
+	enab        namedLevelEnabler
+	forced      bool
+	forcedLevel zapcore.Level
+}
+
+func newNamedLevelCore(core zapcore.Core, enab namedLevelEnabler) zapcore.Core {
+	return &namedLevelCore{Core: core, enab: enab}
+}
+
+func (c *namedLevelCore) Enabled(lvl zapcore.Level) bool {
+	return c.enab.Enabled(lvl)
+}
+
+func (c *namedLevelCore) enabledFor(name string, lvl zapcore.Level) bool {
+	// The cores below only write the forced levels while AtLevel runs.
+	if c.forced && lvl >= c.forcedLevel && forcedEnabled(lvl) {
+		return true
+	}
+	return c.enab.enabledFor(name, lvl)
+}
+
+// atLevel returns a copy of c forced to write the entries at lvl and above.
+func (c *namedLevelCore) atLevel(lvl zapcore.Level) *namedLevelCore {
+	forced := *c
+	forced.forced, forced.forcedLevel = true, lvl
+	return &forced
+}
+
+func (c *namedLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	child := *c
+	child.Core = c.Core.With(fields)
+	return &child
+}
+
+func (c *namedLevelCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.enabledFor(entry.LoggerName, entry.Level) {
+		return ce
+	}
+	return c.Core.Check(entry, ce)
+}
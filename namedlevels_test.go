@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetNamedLevel(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	defer ClearNamedLevel("txmanager")
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.jsonl")
+
+	config := productionConfig(true, zapcore.InfoLevel)
+	config.OutputPaths = []string{path}
+	l, err := buildLogger(config)
+	require.NoError(t, err)
+	defer l.Close()
+
+	SetNamedLevel("txmanager", zapcore.WarnLevel)
+	SetNamedLevel("txmanager", zapcore.DebugLevel)
+	txm := l.Named("txmanager")
+	assert.True(t, txm.DebugEnabled())
+	assert.False(t, l.DebugEnabled())
+	assert.False(t, l.Named("other").DebugEnabled())
+
+	before := testutil.ToFloat64(getCounters().debugLines)
+	txm.Debugw("overridden")
+	txm.Named("broadcaster").Debugw("inherited")
+	l.Named("other").Debugw("other name")
+	l.Debugw("unnamed")
+	l.Named("other").Desugar().Debug("other name, unsugared")
+	l.Named("other").Infow("global level")
+	assert.Equal(t, before+2, testutil.ToFloat64(getCounters().debugLines))
+
+	ClearNamedLevel("txmanager")
+	txm.Debugw("cleared")
+	assert.False(t, txm.DebugEnabled())
+
+	require.NoError(t, l.Sync())
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `"msg":"overridden"`)
+	assert.Contains(t, lines[1], `"logger":"txmanager.broadcaster","caller"`)
+	assert.Contains(t, lines[2], `"msg":"global level"`)
+}
+
+func TestSetNamedLevel_Raised(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	defer ClearNamedLevel("noisy")
+	config := productionConfig(true, zapcore.DebugLevel)
+	config.OutputPaths = nil
+	l, err := buildLogger(config)
+	require.NoError(t, err)
+
+	SetNamedLevel("noisy", zapcore.ErrorLevel)
+	assert.False(t, l.Named("noisy").WarnEnabled())
+	assert.True(t, l.Named("noisy").ErrorEnabled())
+	assert.True(t, l.Named("quiet").DebugEnabled())
+}
@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"net/url"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Option customizes the configuration of the loggers built by the
+// production constructors. Options are applied once the outputs of the
+// logger are known.
+type Option func(*zap.Config)
+
+// applyOptions customizes config with opts, in order.
+func applyOptions(config *zap.Config, opts []Option) {
+	for _, opt := range opts {
+		opt(config)
+	}
+}
+
+// WithTimeEncoder sets how entry timestamps are encoded, for example as
+// zapcore.RFC3339TimeEncoder instead of the default epoch seconds. The
+// timestamp is always logged under the "ts" key.
+func WithTimeEncoder(enc zapcore.TimeEncoder) Option {
+	return func(config *zap.Config) {
+		config.EncoderConfig.EncodeTime = enc
+	}
+}
+
+// WithLevelEncoder sets how entry levels are encoded, such as
+// zapcore.CapitalLevelEncoder for "WARN" instead of the default "warn", or
+// a LevelNameEncoder for names of your own, such as "WARNING". The pretty
+// console, PrettyJSON, syslog, journald and Windows Event Log outputs read
+// those of zap's level names in any case, and "warning". The level label of
+// the package's metrics keeps the lowercase names, whatever the encoding.
+func WithLevelEncoder(enc zapcore.LevelEncoder) Option {
+	return func(config *zap.Config) {
+		config.EncoderConfig.EncodeLevel = enc
+	}
+}
+
+// WithDevelopment puts the logger in development mode, in which DPanic,
+// DPanicf and DPanicw panic once the entry is logged, as Panic does, so
+// that the conditions they report fail tests and CI. In production, the
+// default, they only log the entry, at the dpanic level, and return. In a
+// config file, it is the development key.
+func WithDevelopment() Option {
+	return func(config *zap.Config) {
+		config.Development = true
+	}
+}
+
+// WithProcessFields adds the hostname of the machine, the pid of the process
+// and the version of Go it was built with to every entry, under the
+// hostname, pid and go_version keys. The hostname is left out if it can't
+// be read.
+func WithProcessFields() Option {
+	return func(config *zap.Config) {
+		if config.InitialFields == nil {
+			config.InitialFields = map[string]interface{}{}
+		}
+		if hostname, err := os.Hostname(); err == nil {
+			config.InitialFields["hostname"] = hostname
+		}
+		config.InitialFields["pid"] = os.Getpid()
+		config.InitialFields["go_version"] = runtime.Version()
+	}
+}
+
+// WithoutStacktraces stops stack traces from being added to the entries
+// logged at the error level and above.
+func WithoutStacktraces() Option {
+	return func(config *zap.Config) {
+		config.DisableStacktrace = true
+	}
+}
+
+// WithoutCaller stops entries from being annotated with the file and line
+// they were logged from, saving the runtime.Caller lookup made for every
+// entry. See BenchmarkLogger_Caller for the difference it makes.
+func WithoutCaller() Option {
+	return func(config *zap.Config) {
+		config.DisableCaller = true
+	}
+}
+
+// WithCallerFunction adds the fully qualified name of the function entries
+// were logged from, such as "github.com/org/repo/pkg.(*Type).Method", under
+// the "function" key, next to the file and line of the caller, for logs to
+// be grepped by function regardless of the lines moved by refactors. With
+// instead, the function replaces the file and line as the caller.
+func WithCallerFunction(instead bool) Option {
+	return func(config *zap.Config) {
+		if instead {
+			config.EncoderConfig.EncodeCaller = FunctionCallerEncoder
+			return
+		}
+		config.EncoderConfig.FunctionKey = "function"
+	}
+}
+
+// FunctionCallerEncoder encodes the caller as the fully qualified name of
+// its function, or as file:line if it is unknown.
+func FunctionCallerEncoder(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+	function := caller.Function
+	if function == "" {
+		if fn := runtime.FuncForPC(caller.PC); fn != nil {
+			function = fn.Name()
+		}
+	}
+	if function == "" {
+		zapcore.ShortCallerEncoder(caller, enc)
+		return
+	}
+	enc.AppendString(function)
+}
+
+// WithPrettyFields pins the fields with the given keys to the front of the
+// details of the pretty console, in order, hiding the other fields unless
+// verbose is set. The other outputs of the logger are left as they are.
+func WithPrettyFields(verbose bool, fields ...string) Option {
+	return func(config *zap.Config) {
+		setPrettyQuery(config, func(query url.Values) {
+			query.Set("fields", strings.Join(fields, ","))
+			query.Set("verbose", strconv.FormatBool(verbose))
+		})
+	}
+}
+
+// WithPrettyTruncation truncates, in the pretty console, the field values
+// longer than valueWidth characters and the messages longer than
+// messageWidth, ending them with an ellipsis, to keep the console
+// scannable; either width may be 0 to leave those whole. The other outputs
+// of the logger, such as log files, are left as they are.
+func WithPrettyTruncation(valueWidth, messageWidth int) Option {
+	return func(config *zap.Config) {
+		setPrettyQuery(config, func(query url.Values) {
+			query.Set("maxValueWidth", strconv.Itoa(valueWidth))
+			query.Set("maxMessageWidth", strconv.Itoa(messageWidth))
+		})
+	}
+}
+
+// setPrettyQuery updates the query parameters of the pretty console output
+// paths of config with set.
+func setPrettyQuery(config *zap.Config, set func(url.Values)) {
+	for i, path := range config.OutputPaths {
+		if !strings.HasPrefix(path, "pretty:") {
+			continue
+		}
+		u, err := url.Parse(path)
+		if err != nil {
+			continue
+		}
+		query := u.Query()
+		set(query)
+		u.RawQuery = query.Encode()
+		config.OutputPaths[i] = u.String()
+	}
+}
+
+// WithBufferedWrites buffers up to size bytes of entries in memory before
+// writing them to the outputs other than the pretty consoles and the recent
+// logs, flushing the buffer every flushInterval, on Sync, and when an entry
+// above the error level is logged. This keeps slow disks off the logging
+// path, at the cost of losing the tail of the buffer if the process
+// crashes, or is killed, before it is flushed.
+func WithBufferedWrites(size int, flushInterval time.Duration) Option {
+	return func(config *zap.Config) {
+		for i, path := range config.OutputPaths {
+			if !strings.HasPrefix(path, "pretty:") && !strings.HasPrefix(path, "prettyjson:") && !isRecentPath(path) {
+				config.OutputPaths[i] = bufferedURI(path, size, flushInterval)
+			}
+		}
+	}
+}
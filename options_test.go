@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithTimeEncoder(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	config := productionConfig(true, zapcore.InfoLevel)
+	applyOptions(&config, []Option{WithTimeEncoder(zapcore.RFC3339TimeEncoder)})
+	assert.Equal(t, "ts", config.EncoderConfig.TimeKey)
+
+	enc := zapcore.NewJSONEncoder(config.EncoderConfig)
+	buf, err := enc.EncodeEntry(zapcore.Entry{Time: time.Date(2018, 4, 12, 12, 55, 28, 0, time.UTC)}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `"ts":"2018-04-12T12:55:28Z"`)
+}
+
+func TestWithLevelEncoder(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	encode := func(enc zapcore.LevelEncoder, lvl zapcore.Level) string {
+		config := productionConfig(true, zapcore.InfoLevel)
+		applyOptions(&config, []Option{WithLevelEncoder(enc)})
+		e, err := newEncoder(config)
+		require.NoError(t, err)
+		buf, err := e.EncodeEntry(zapcore.Entry{Level: lvl}, nil)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	assert.Contains(t, encode(zapcore.CapitalLevelEncoder, zapcore.WarnLevel), `"level":"WARN"`)
+	assert.Contains(t, encode(zapcore.CapitalLevelEncoder, TraceLevel), `"level":"TRACE"`)
+	assert.Contains(t, encode(zapcore.LowercaseLevelEncoder, TraceLevel), `"level":"trace"`)
+
+	names := LevelNameEncoder(map[zapcore.Level]string{
+		zapcore.WarnLevel:  "WARNING",
+		zapcore.ErrorLevel: "ERROR",
+		TraceLevel:         "FINEST",
+	})
+	assert.Contains(t, encode(names, zapcore.WarnLevel), `"level":"WARNING"`)
+	assert.Contains(t, encode(names, zapcore.ErrorLevel), `"level":"ERROR"`)
+	assert.Contains(t, encode(names, zapcore.InfoLevel), `"level":"info"`)
+	assert.Contains(t, encode(names, TraceLevel), `"level":"FINEST"`)
+	assert.Contains(t, encode(LevelNameEncoder(nil), TraceLevel), `"level":"trace"`)
+}
+
+func TestWithLevelEncoder_CanonicalMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	config := zap.NewProductionEncoderConfig()
+	config.EncodeLevel = zapcore.CapitalLevelEncoder
+	core := newByteCountingCore(zapcore.NewCore(zapcore.NewJSONEncoder(config), zapcore.AddSync(&buf), zapcore.InfoLevel), zapcore.NewJSONEncoder(config))
+	l := NewLogger(zap.New(core))
+
+	before := testutil.ToFloat64(getCounters().bytes.WithLabelValues("warn"))
+	l.Warn("counted")
+	assert.Contains(t, buf.String(), `"level":"WARN"`)
+	assert.Greater(t, testutil.ToFloat64(getCounters().bytes.WithLabelValues("warn")), before)
+}
+
+func TestBuildOptions_Stacktrace(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+
+	observed := func(opts ...Option) *observer.ObservedLogs {
+		config := productionConfig(true, zapcore.InfoLevel)
+		applyOptions(&config, opts)
+		core, logs := observer.New(zapcore.InfoLevel)
+		zl, err := config.Build(append(buildOptions(config), zap.WrapCore(func(zapcore.Core) zapcore.Core {
+			return core
+		}))...)
+		require.NoError(t, err)
+		l := NewLogger(zl)
+		l.Warnw("warned")
+		l.Errorw("failed", "key", "value")
+		return logs
+	}
+
+	entries := observed().All()
+	require.Len(t, entries, 2)
+	assert.Empty(t, entries[0].Stack)
+	assert.Contains(t, entries[1].Stack, "TestBuildOptions_Stacktrace")
+
+	entries = observed(WithoutStacktraces()).All()
+	require.Len(t, entries, 2)
+	assert.Empty(t, entries[1].Stack)
+}
+
+func TestWithoutCaller(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+
+	observed := func(opts ...Option) *observer.ObservedLogs {
+		config := productionConfig(true, zapcore.InfoLevel)
+		applyOptions(&config, opts)
+		core, logs := observer.New(zapcore.InfoLevel)
+		zl, err := config.Build(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+			return core
+		}))
+		require.NoError(t, err)
+		zl.Info("logged")
+		return logs
+	}
+
+	assert.True(t, observed().All()[0].Caller.Defined)
+	assert.False(t, observed(WithoutCaller()).All()[0].Caller.Defined)
+}
+
+func TestWithCallerFunction(t *testing.T) {
+	logged := func(opts ...Option) map[string]interface{} {
+		config := productionConfig(true, zapcore.InfoLevel)
+		applyOptions(&config, opts)
+		var buf bytes.Buffer
+		core := zapcore.NewCore(zapcore.NewJSONEncoder(config.EncoderConfig), zapcore.AddSync(&buf), zapcore.InfoLevel)
+		NewLogger(zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))).Info("logged")
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		return entry
+	}
+	const function = "github.com/smartcontractkit/logger.TestWithCallerFunction.func1"
+
+	entry := logged()
+	assert.Contains(t, entry["caller"], "options_test.go:")
+	assert.NotContains(t, entry, "function")
+
+	entry = logged(WithCallerFunction(false))
+	assert.Contains(t, entry["caller"], "options_test.go:")
+	assert.Equal(t, function, entry["function"])
+
+	entry = logged(WithCallerFunction(true))
+	assert.Equal(t, function, entry["caller"])
+	assert.NotContains(t, entry, "function")
+}
+
+func TestFunctionCallerEncoder(t *testing.T) {
+	obj := zapcore.NewMapObjectEncoder()
+	require.NoError(t, obj.AddArray("caller", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		FunctionCallerEncoder(zapcore.NewEntryCaller(0, "/src/pkg/file.go", 12, true), arr)
+		return nil
+	})))
+	assert.Equal(t, []interface{}{"pkg/file.go:12"}, obj.Fields["caller"], "unknown functions fall back to file:line")
+}
+
+func TestWithDevelopment(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+
+	build := func(opts ...Option) *Logger {
+		config := productionConfig(true, zapcore.InfoLevel)
+		config.OutputPaths = nil
+		applyOptions(&config, opts)
+		l, err := buildLogger(config)
+		require.NoError(t, err)
+		return l
+	}
+
+	before := testutil.ToFloat64(getCounters().dPanicLines)
+	assert.NotPanics(t, func() { build().DPanic("logged") })
+	assert.Panics(t, func() { build(WithDevelopment()).DPanicw("panicked") })
+	assert.Equal(t, before+2, testutil.ToFloat64(getCounters().dPanicLines))
+}
+
+func TestWithProcessFields(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	config := productionConfig(true, zapcore.InfoLevel)
+	config.InitialFields = map[string]interface{}{"service": "node"}
+	applyOptions(&config, []Option{WithProcessFields()})
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"service":    "node",
+		"hostname":   hostname,
+		"pid":        os.Getpid(),
+		"go_version": runtime.Version(),
+	}, config.InitialFields)
+}
+
+func TestWithPrettyFields(t *testing.T) {
+	config := productionConfig(false, zapcore.InfoLevel)
+	config.OutputPaths = append(config.OutputPaths, "/var/log/log.jsonl")
+	applyOptions(&config, []Option{WithPrettyFields(false, "request_id", "error")})
+
+	assert.Equal(t, []string{"pretty://console?fields=request_id%2Cerror&verbose=false", "/var/log/log.jsonl"}, config.OutputPaths)
+}
+
+func TestWithPrettyTruncation(t *testing.T) {
+	config := productionConfig(false, zapcore.InfoLevel)
+	config.OutputPaths = append(config.OutputPaths, "/var/log/log.jsonl")
+	applyOptions(&config, []Option{WithPrettyTruncation(80, 0)})
+	assert.Equal(t, []string{"pretty://console?maxMessageWidth=0&maxValueWidth=80", "/var/log/log.jsonl"}, config.OutputPaths)
+
+	u, err := url.Parse(config.OutputPaths[0])
+	require.NoError(t, err)
+	sink, err := prettyConsoleSink(u)
+	require.NoError(t, err)
+	assert.Equal(t, 80, sink.(PrettyConsole).MaxValueWidth)
+	assert.Equal(t, 0, sink.(PrettyConsole).MaxMessageWidth)
+
+	u, err = url.Parse("pretty://console?maxValueWidth=wide")
+	require.NoError(t, err)
+	_, err = prettyConsoleSink(u)
+	assert.Error(t, err)
+}
@@ -0,0 +1,234 @@
+// Package otlplog exports entries logged through the logger package as
+// OpenTelemetry log records, correlated with the traces their TraceID and
+// SpanID fields belong to.
+//
+// It is a separate module, as the OpenTelemetry logs SDK requires a more
+// recent Go than the logger package.
+package otlplog
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/smartcontractkit/logger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+// flushTimeout bounds how long Sync, and entries about to panic or exit,
+// wait for queued records to be exported.
+const flushTimeout = 5 * time.Second
+
+// scopeName is the instrumentation scope the records are emitted under.
+const scopeName = "github.com/smartcontractkit/logger"
+
+// EnableOTLP tees the package logger so that its entries are also exported
+// to the OTLP/HTTP collector at endpoint, such as http://localhost:4318.
+func EnableOTLP(endpoint string) error {
+	exporter, err := otlploghttp.New(context.Background(), otlploghttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return err
+	}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)))
+	c := NewCore(provider, levelEnablerFunc(logger.GetLogLevel))
+
+	logger.WrapPackageCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, c)
+	})
+	return nil
+}
+
+// NewCore returns a zapcore.Core emitting the entries enabled by enab as log
+// records of the given provider. Structured fields are attached as
+// attributes, and the TraceID and SpanID fields, when present, as the trace
+// context of the record.
+func NewCore(provider log.LoggerProvider, enab zapcore.LevelEnabler) zapcore.Core {
+	return &core{LevelEnabler: enab, provider: provider, logger: provider.Logger(scopeName)}
+}
+
+// levelEnablerFunc adapts a level getter, such as logger.GetLogLevel, to a
+// zapcore.LevelEnabler following its changes.
+type levelEnablerFunc func() zapcore.Level
+
+func (f levelEnablerFunc) Enabled(lvl zapcore.Level) bool { return f().Enabled(lvl) }
+
+type core struct {
+	zapcore.LevelEnabler
+	provider log.LoggerProvider
+	logger   log.Logger
+	fields   []zapcore.Field
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(clone.fields[:len(clone.fields):len(clone.fields)], fields...)
+	return &clone
+}
+
+func (c *core) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	var record log.Record
+	record.SetTimestamp(entry.Time)
+	record.SetObservedTimestamp(time.Now())
+	record.SetSeverity(severity(entry.Level))
+	record.SetSeverityText(severityText(entry.Level))
+	record.SetBody(log.StringValue(entry.Message))
+	if entry.LoggerName != "" {
+		record.AddAttributes(log.String("logger", entry.LoggerName))
+	}
+	if entry.Caller.Defined {
+		record.AddAttributes(log.String("caller", entry.Caller.TrimmedPath()))
+	}
+	if entry.Stack != "" {
+		record.AddAttributes(log.String("stacktrace", entry.Stack))
+	}
+
+	ctx := context.Background()
+	if sc, ok := spanContext(enc.Fields); ok {
+		ctx = trace.ContextWithSpanContext(ctx, sc)
+		delete(enc.Fields, logger.TraceId)
+		delete(enc.Fields, logger.SpanId)
+	}
+	for k, v := range enc.Fields {
+		record.AddAttributes(log.KeyValue{Key: k, Value: value(v)})
+	}
+
+	c.logger.Emit(ctx, record)
+	if entry.Level > zapcore.ErrorLevel {
+		// The process may be about to panic or exit, don't lose the record.
+		return c.Sync()
+	}
+	return nil
+}
+
+func (c *core) Sync() error {
+	flusher, ok := c.provider.(interface{ ForceFlush(context.Context) error })
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	return flusher.ForceFlush(ctx)
+}
+
+// spanContext returns the span context of the TraceID and SpanID fields.
+func spanContext(fields map[string]interface{}) (trace.SpanContext, bool) {
+	traceID, err := trace.TraceIDFromHex(fmt.Sprint(fields[logger.TraceId]))
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+	config := trace.SpanContextConfig{TraceID: traceID, TraceFlags: trace.FlagsSampled}
+	if spanID, err := trace.SpanIDFromHex(fmt.Sprint(fields[logger.SpanId])); err == nil {
+		config.SpanID = spanID
+	}
+	return trace.NewSpanContext(config), true
+}
+
+// value converts a field value encoded by zapcore.MapObjectEncoder to a log
+// attribute value.
+func value(v interface{}) log.Value {
+	switch v := v.(type) {
+	case string:
+		return log.StringValue(v)
+	case bool:
+		return log.BoolValue(v)
+	case int:
+		return log.IntValue(v)
+	case int8:
+		return log.Int64Value(int64(v))
+	case int16:
+		return log.Int64Value(int64(v))
+	case int32:
+		return log.Int64Value(int64(v))
+	case int64:
+		return log.Int64Value(v)
+	case uint8:
+		return log.Int64Value(int64(v))
+	case uint16:
+		return log.Int64Value(int64(v))
+	case uint32:
+		return log.Int64Value(int64(v))
+	case uint:
+		return uintValue(uint64(v))
+	case uint64:
+		return uintValue(v)
+	case uintptr:
+		return uintValue(uint64(v))
+	case float32:
+		return log.Float64Value(float64(v))
+	case float64:
+		return log.Float64Value(v)
+	case []interface{}:
+		values := make([]log.Value, len(v))
+		for i, e := range v {
+			values[i] = value(e)
+		}
+		return log.SliceValue(values...)
+	case map[string]interface{}:
+		kvs := make([]log.KeyValue, 0, len(v))
+		for k, e := range v {
+			kvs = append(kvs, log.KeyValue{Key: k, Value: value(e)})
+		}
+		return log.MapValue(kvs...)
+	default:
+		return log.StringValue(fmt.Sprint(v))
+	}
+}
+
+// uintValue converts n to an int64 value, or to a string value if it
+// overflows.
+func uintValue(n uint64) log.Value {
+	if n > math.MaxInt64 {
+		return log.StringValue(fmt.Sprint(n))
+	}
+	return log.Int64Value(int64(n))
+}
+
+func severity(lvl zapcore.Level) log.Severity {
+	switch lvl {
+	case logger.TraceLevel:
+		return log.SeverityTrace
+	case zapcore.DebugLevel:
+		return log.SeverityDebug
+	case zapcore.InfoLevel:
+		return log.SeverityInfo
+	case zapcore.WarnLevel:
+		return log.SeverityWarn
+	case zapcore.ErrorLevel:
+		return log.SeverityError
+	case zapcore.DPanicLevel:
+		return log.SeverityFatal
+	case zapcore.PanicLevel:
+		return log.SeverityFatal2
+	default:
+		return log.SeverityFatal4
+	}
+}
+
+// severityText returns the name of lvl, which zap doesn't know for the trace
+// level.
+func severityText(lvl zapcore.Level) string {
+	if lvl == logger.TraceLevel {
+		return "trace"
+	}
+	return lvl.String()
+}
@@ -0,0 +1,106 @@
+package otlplog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/smartcontractkit/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewCore(t *testing.T) {
+	exporter := &testExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	l := logger.NewLogger(zap.New(NewCore(provider, zapcore.InfoLevel)))
+
+	l.Debugw("ignored")
+	l.With("TraceID", "0102030405060708090a0b0c0d0e0f10", "SpanID", "0102030405060708").
+		Warnw("exported", "key", "value", "n", 3)
+	require.NoError(t, l.Sync())
+
+	records := exporter.Records()
+	require.Len(t, records, 1)
+	r := records[0]
+	assert.Equal(t, "exported", r.Body().AsString())
+	assert.Equal(t, log.SeverityWarn, r.Severity())
+	assert.Equal(t, "warn", r.SeverityText())
+	assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", r.TraceID().String())
+	assert.Equal(t, "0102030405060708", r.SpanID().String())
+
+	attrs := map[string]log.Value{}
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	assert.Equal(t, map[string]log.Value{
+		"key": log.StringValue("value"),
+		"n":   log.Int64Value(3),
+	}, attrs)
+}
+
+func TestNewCore_TraceLevel(t *testing.T) {
+	exporter := &testExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	l := logger.NewLogger(zap.New(NewCore(provider, logger.TraceLevel)))
+
+	l.Tracew("diagnostic", "key", "value")
+	require.NoError(t, l.Sync())
+
+	records := exporter.Records()
+	require.Len(t, records, 1)
+	assert.Equal(t, log.SeverityTrace, records[0].Severity())
+	assert.Equal(t, "trace", records[0].SeverityText())
+}
+
+func TestEnableOTLP_KeepsSinks(t *testing.T) {
+	defer logger.SetLogLevel(logger.GetLogLevel())
+	defer logger.ReplaceLogger(logger.GetLogger())
+	collector := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer collector.Close()
+	dir := t.TempDir()
+
+	l, err := logger.NewProductionLogger(dir, true, zapcore.InfoLevel, true)
+	require.NoError(t, err)
+	logger.ReplaceLogger(l)
+	require.NoError(t, EnableOTLP(collector.URL))
+	logger.Infow("logged to disk")
+
+	require.NoError(t, logger.Close())
+	b, err := os.ReadFile(filepath.Join(dir, "log.jsonl"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"msg":"logged to disk"`)
+	assert.Error(t, logger.Sync(), "the log file should be closed")
+}
+
+type testExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *testExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *testExporter) Records() []sdklog.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.records
+}
+
+func (*testExporter) Shutdown(context.Context) error   { return nil }
+func (*testExporter) ForceFlush(context.Context) error { return nil }
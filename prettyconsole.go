@@ -1,35 +1,152 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"math"
+	"os"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/tidwall/gjson"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 var levelColors = map[string]func(...interface{}) string{
-	"default": color.New(color.FgWhite).SprintFunc(),
-	"debug":   color.New(color.FgGreen).SprintFunc(),
-	"info":    color.New(color.FgWhite).SprintFunc(),
-	"warn":    color.New(color.FgYellow).SprintFunc(),
-	"error":   color.New(color.FgRed).SprintFunc(),
-	"panic":   color.New(color.FgRed).SprintFunc(),
-	"fatal":   color.New(color.FgRed).SprintFunc(),
+	"default": newColor(color.FgWhite),
+	"trace":   newColor(color.FgCyan),
+	"debug":   newColor(color.FgGreen),
+	"info":    newColor(color.FgWhite),
+	"warn":    newColor(color.FgYellow),
+	"warning": newColor(color.FgYellow),
+	"error":   newColor(color.FgRed),
+	"panic":   newColor(color.FgRed),
+	"fatal":   newColor(color.FgRed),
 }
 
-var blue = color.New(color.FgBlue).SprintFunc()
-var green = color.New(color.FgGreen).SprintFunc()
+// levelColorOverrides holds the map[string]func(...interface{}) string of
+// the level colors set with SetLevelColors, by level name.
+var levelColorOverrides atomic.Value
+
+// sgrParameters matches the parameters of an ANSI color code.
+var sgrParameters = regexp.MustCompile(`^[0-9]+(;[0-9]+)*$`)
+
+func init() {
+	levelColorOverrides.Store(map[string]func(...interface{}) string{})
+}
+
+// SetLevelColors overrides the colors the levels are written in by the
+// pretty console, and by PrettyJSON, for the themes in which the default
+// ones are hard to read. Colors are the parameters of ANSI color codes, such
+// as "36" for cyan or "1;34" for bold blue; other values are ignored. The
+// levels missing from colors keep their default color, and a nil map
+// restores them all.
+//
+// The colors are only written when the output is colored, so never when
+// the NO_COLOR environment variable is set, unless forced with ColorAlways.
+func SetLevelColors(colors map[zapcore.Level]string) {
+	overrides := make(map[string]func(...interface{}) string, len(colors))
+	for lvl, code := range colors {
+		if !sgrParameters.MatchString(code) {
+			continue
+		}
+		overrides[levelName(lvl)] = ansiColor(code)
+		if lvl == zapcore.WarnLevel {
+			overrides["warning"] = overrides["warn"]
+		}
+	}
+	levelColorOverrides.Store(overrides)
+}
+
+// ansiColor colors text with the ANSI color code of the given parameters.
+func ansiColor(code string) func(...interface{}) string {
+	return func(a ...interface{}) string {
+		return "\x1b[" + code + "m" + fmt.Sprint(a...) + "\x1b[0m"
+	}
+}
+
+// levelColor returns the color of the named level, overridden or default.
+func levelColor(name string) (func(...interface{}) string, bool) {
+	name = strings.ToLower(name)
+	if color, ok := levelColorOverrides.Load().(map[string]func(...interface{}) string)[name]; ok {
+		return color, true
+	}
+	color, ok := levelColors[name]
+	return color, ok
+}
+
+var blue = newColor(color.FgBlue)
+var green = newColor(color.FgGreen)
+var magenta = newColor(color.FgMagenta)
+
+// newColor always colors, regardless of color.NoColor: whether the colors
+// are kept is decided per PrettyConsole.
+func newColor(attr color.Attribute) func(...interface{}) string {
+	c := color.New(attr)
+	c.EnableColor()
+	return c.SprintFunc()
+}
+
+// ansiEscapes matches the color codes stripped from uncolored output.
+var ansiEscapes = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// ColorMode controls whether PrettyConsole colors its output.
+type ColorMode int
+
+const (
+	// ColorAuto colors output only when the sink is a terminal and the
+	// NO_COLOR environment variable is unset.
+	ColorAuto ColorMode = iota
+	// ColorAlways colors output unconditionally.
+	ColorAlways
+	// ColorNever never colors output.
+	ColorNever
+)
+
+// DefaultPrettyTimeLayout is the layout PrettyConsole formats timestamps
+// with when TimeLayout is empty: RFC3339 with millisecond precision.
+const DefaultPrettyTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// DefaultPrettyMaxDepth is the nesting depth PrettyConsole renders the
+// objects and arrays of fields to when MaxDepth is 0.
+const DefaultPrettyMaxDepth = 4
 
 // PrettyConsole wraps a Sink (Writer, Syncer, Closer), usually stdout, and
 // formats the incoming json bytes with colors and white space for readability
 // before passing on to the underlying Writer in Sink.
 type PrettyConsole struct {
 	zap.Sink
+	// TimeLayout is the time.Format layout of the headline timestamp,
+	// DefaultPrettyTimeLayout if empty.
+	TimeLayout string
+	// Color controls whether the output is colored, ColorAuto by default.
+	Color ColorMode
+	// Fields, if set, lists the keys of the fields shown first, in order.
+	// The other fields are then only shown when Verbose is set. Either way,
+	// every field is still written to the other outputs of the logger.
+	Fields []string
+	// Verbose shows the fields missing from Fields after the listed ones.
+	Verbose bool
+	// MaxDepth is the nesting depth the objects and arrays of fields, such
+	// as the structs and maps logged with Infow, are rendered to as
+	// compact json, DefaultPrettyMaxDepth if 0. Those nested deeper are
+	// elided as {…} or […], to keep large values from flooding the console.
+	MaxDepth int
+	// MaxValueWidth, if positive, truncates the field values longer than
+	// that many characters, ending them with an ellipsis. Values spanning
+	// several lines, such as stack traces, are kept whole.
+	MaxValueWidth int
+	// MaxMessageWidth, if positive, likewise truncates the message.
+	MaxMessageWidth int
 }
 
 // Write reformats the incoming json bytes with colors, newlines and whitespace
@@ -39,20 +156,64 @@ func (pc PrettyConsole) Write(b []byte) (int, error) {
 		return 0, fmt.Errorf("unable to parse json for pretty console: %s", string(b))
 	}
 	js := gjson.ParseBytes(b)
-	headline := generateHeadline(js)
-	details := generateDetails(js)
-	return pc.Sink.Write([]byte(fmt.Sprintln(headline, details)))
+	headline := generateHeadline(js, pc.timeLayout(), pc.MaxMessageWidth)
+	details := generateDetails(js, pc.Fields, pc.Verbose, pc.maxDepth(), pc.MaxValueWidth)
+	out := []byte(fmt.Sprintln(headline, details))
+	if !pc.colored() {
+		out = ansiEscapes.ReplaceAll(out, nil)
+	}
+	return pc.Sink.Write(out)
+}
+
+// colored reports whether the output should keep its color codes.
+func (pc PrettyConsole) colored() bool {
+	return colored(pc.Color, pc.Sink)
+}
+
+// colored reports whether output written to sink in the given mode should
+// be colored.
+func colored(mode ColorMode, sink zap.Sink) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := sink.(interface{ Fd() uintptr })
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
 }
 
-func generateHeadline(js gjson.Result) string {
-	sec, dec := math.Modf(js.Get("ts").Float())
+func (pc PrettyConsole) maxDepth() int {
+	if pc.MaxDepth <= 0 {
+		return DefaultPrettyMaxDepth
+	}
+	return pc.MaxDepth
+}
+
+func (pc PrettyConsole) timeLayout() string {
+	if pc.TimeLayout == "" {
+		return DefaultPrettyTimeLayout
+	}
+	return pc.TimeLayout
+}
+
+// generateHeadline renders the timestamp, level, message, truncated to
+// maxMessageWidth if positive, and caller of the entry.
+func generateHeadline(js gjson.Result, layout string, maxMessageWidth int) string {
 	headline := []interface{}{
-		iso8601UTC(time.Unix(int64(sec), int64(dec*(1e9)))),
+		parseTimestamp(js.Get("ts")).UTC().Format(layout),
 		" ",
 		coloredLevel(js.Get("level")),
-		fmt.Sprintf("%-50s", js.Get("msg")),
+		fmt.Sprintf("%-50s", truncate(js.Get("msg").String(), maxMessageWidth)),
 		" ",
-		fmt.Sprintf("%-32s", blue(js.Get("caller"))),
+	}
+	// Loggers built without caller annotation log no caller, in which case
+	// the column is left out rather than padded.
+	if caller := js.Get("caller"); caller.Exists() {
+		headline = append(headline, fmt.Sprintf("%-32s", blue(caller)))
 	}
 	return fmt.Sprint(headline...)
 }
@@ -67,37 +228,149 @@ var detailsBlacklist = map[string]bool{
 	"hash":   true,
 }
 
-func generateDetails(js gjson.Result) string {
+// generateDetails renders the fields of the entry as key=value pairs: the
+// pinned ones first, in order, then the others sorted by key, unless they
+// are hidden. Without pinned fields, every field is shown, with the TraceID
+// in front. Values spanning several lines, such as stack traces, are set
+// apart on the lines that follow, objects and arrays are rendered down to
+// maxDepth, and the other values are truncated to maxValueWidth if positive.
+func generateDetails(js gjson.Result, pinned []string, verbose bool, maxDepth, maxValueWidth int) string {
 	data := js.Map()
+	if len(pinned) == 0 {
+		pinned, verbose = []string{TraceId}, true
+	}
+	isPinned := map[string]bool{}
 	keys := []string{}
 
-	for k := range data {
+	for _, k := range pinned {
+		if !isPinned[k] {
+			isPinned[k] = true
+			keys = append(keys, k)
+		}
+	}
+	if verbose {
+		rest := []string{}
+		for k := range data {
+			if !isPinned[k] {
+				rest = append(rest, k)
+			}
+		}
+		sort.Strings(rest)
+		keys = append(keys, rest...)
+	}
+
+	var details, multiline strings.Builder
+
+	for _, k := range keys {
 		if detailsBlacklist[k] || len(data[k].String()) == 0 {
 			continue
 		}
-		keys = append(keys, k)
+		value := formatDetail(data[k], maxDepth)
+		if strings.Contains(value, "\n") {
+			value = strings.Replace(strings.TrimRight(value, "\n"), "\n", "\n\t", -1)
+			multiline.WriteString(fmt.Sprintf("\n%s:\n\t%s", green(k), value))
+			continue
+		}
+		value = truncate(value, maxValueWidth)
+		key := green(k)
+		if k == TraceId {
+			key, value = magenta(k), magenta(value)
+		}
+		details.WriteString(fmt.Sprintf("%s=%s ", key, value))
 	}
 
-	sort.Strings(keys)
+	return details.String() + multiline.String()
+}
 
-	var details strings.Builder
+// formatDetail renders a field value: nested objects and arrays as compact
+// json down to maxDepth, and strings quoted when they would otherwise be
+// ambiguous.
+func formatDetail(value gjson.Result, maxDepth int) string {
+	switch {
+	case value.IsObject(), value.IsArray():
+		var compacted bytes.Buffer
+		writeCompact(&compacted, value, maxDepth)
+		return compacted.String()
+	case value.Type == gjson.String:
+		s := value.String()
+		if !strings.Contains(s, "\n") && strings.ContainsAny(s, " =\"\t") {
+			return strconv.Quote(s)
+		}
+		return s
+	default:
+		return value.Raw
+	}
+}
 
-	for _, v := range keys {
-		details.WriteString(fmt.Sprintf("%s=%v ", green(v), data[v]))
+// truncate shortens s to width characters, the last of them an ellipsis, if
+// width is positive and s is longer.
+func truncate(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
 	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
 
-	return details.String()
+// writeCompact writes value as compact json, eliding the objects and arrays
+// nested below depth.
+func writeCompact(out *bytes.Buffer, value gjson.Result, depth int) {
+	if !value.IsObject() && !value.IsArray() {
+		if err := json.Compact(out, []byte(value.Raw)); err != nil {
+			out.WriteString(value.Raw)
+		}
+		return
+	}
+	open, end := "[", "]"
+	if value.IsObject() {
+		open, end = "{", "}"
+	}
+	if depth <= 0 {
+		out.WriteString(open + "…" + end)
+		return
+	}
+	out.WriteString(open)
+	first := true
+	value.ForEach(func(k, v gjson.Result) bool {
+		if !first {
+			out.WriteByte(',')
+		}
+		first = false
+		if k.Exists() {
+			out.WriteString(k.Raw + ":")
+		}
+		writeCompact(out, v, depth-1)
+		return true
+	})
+	out.WriteString(end)
 }
 
 func coloredLevel(level gjson.Result) string {
-	color, ok := levelColors[level.String()]
+	color, ok := levelColor(level.String())
 	if !ok {
 		color = levelColors["default"]
 	}
 	return color(fmt.Sprintf("%-8s", fmt.Sprint("[", strings.ToUpper(level.String()), "]")))
 }
 
-// iso8601UTC formats given time to ISO8601.
-func iso8601UTC(t time.Time) string {
-	return t.UTC().Format(time.RFC3339)
+// parseTimestamp reads the "ts" field in any of the encodings WithTimeEncoder
+// can produce: RFC3339 strings, or epoch numbers in seconds, milliseconds or
+// nanoseconds, told apart by their magnitude.
+func parseTimestamp(ts gjson.Result) time.Time {
+	if ts.Type == gjson.String {
+		t, err := time.Parse(time.RFC3339Nano, ts.String())
+		if err != nil {
+			return time.Time{}
+		}
+		return t
+	}
+	switch f := ts.Float(); {
+	case f > 1e17:
+		return time.Unix(0, ts.Int())
+	case f > 1e11:
+		return time.Unix(0, int64(f*float64(time.Millisecond)))
+	default:
+		sec, dec := math.Modf(f)
+		return time.Unix(int64(sec), int64(dec*(1e9)))
+	}
 }
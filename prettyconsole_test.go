@@ -1,15 +1,21 @@
 package logger
 
 import (
+	"flag"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
 )
 
 func TestPrettyConsole_Write(t *testing.T) {
-	color.NoColor = false
-
 	tests := []struct {
 		name      string
 		input     string
@@ -19,19 +25,25 @@ func TestPrettyConsole_Write(t *testing.T) {
 		{
 			"headline",
 			`{"ts":1523537728.7260377, "level":"info", "msg":"top level"}`,
-			"2018-04-12T12:55:28Z \x1b[37m[INFO]  \x1b[0mtop level                                          \x1b[34m\x1b[0m                        \n",
+			"2018-04-12T12:55:28.726Z \x1b[37m[INFO]  \x1b[0mtop level                                           \n",
 			false,
 		},
 		{
 			"details",
 			`{"ts":1523537728, "level":"debug", "msg":"top level", "details":"nuances"}`,
-			"2018-04-12T12:55:28Z \x1b[32m[DEBUG] \x1b[0mtop level                                          \x1b[34m\x1b[0m                        \x1b[32mdetails\x1b[0m=nuances \n",
+			"2018-04-12T12:55:28.000Z \x1b[32m[DEBUG] \x1b[0mtop level                                           \x1b[32mdetails\x1b[0m=nuances \n",
+			false,
+		},
+		{
+			"caller",
+			`{"ts":1523537728, "level":"info", "msg":"top level", "caller":"logger/logger.go:12"}`,
+			"2018-04-12T12:55:28.000Z \x1b[37m[INFO]  \x1b[0mtop level                                          \x1b[34mlogger/logger.go:12\x1b[0m     \n",
 			false,
 		},
 		{
 			"blacklist",
 			`{"ts":1523537728, "level":"warn", "msg":"top level", "hash":"nuances"}`,
-			"2018-04-12T12:55:28Z \x1b[33m[WARN]  \x1b[0mtop level                                          \x1b[34m\x1b[0m                        \n",
+			"2018-04-12T12:55:28.000Z \x1b[33m[WARN]  \x1b[0mtop level                                           \n",
 			false,
 		},
 		{"error", `{"broken":}`, `{}`, true},
@@ -40,7 +52,7 @@ func TestPrettyConsole_Write(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tr := &testReader{}
-			pc := PrettyConsole{tr}
+			pc := PrettyConsole{Sink: tr, Color: ColorAlways}
 			_, err := pc.Write([]byte(tt.input))
 
 			if tt.wantError {
@@ -52,6 +64,205 @@ func TestPrettyConsole_Write(t *testing.T) {
 	}
 }
 
+var update = flag.Bool("update", false, "update the golden files of the tests")
+
+func TestPrettyConsole_Golden(t *testing.T) {
+	inputs := []string{
+		`{"level":"info","ts":1523537728,"caller":"logger/logger.go:12","msg":"no fields"}`,
+		`{"level":"debug","ts":1523537728,"caller":"logger/logger.go:12","msg":"scalars","str":"value","int":42,"float":1.5,"bool":true,"null":null}`,
+		`{"level":"info","ts":1523537728,"msg":"ambiguous strings","spaces":"two words","equals":"a=b","quotes":"say \"hi\"","empty":""}`,
+		`{"level":"warn","ts":1523537728,"caller":"logger/logger.go:12","msg":"nested","map":{ "a": 1, "b": [1, 2, {"c": "d"}] },"slice":[ "x", "y" ]}`,
+		`{"level":"info","ts":1523537728,"caller":"logger/logger.go:12","msg":"traced","SpanID":"00f067aa0ba902b7","TraceID":"4bf92f3577b34da6a3ce929d0e0e4736","key":"value"}`,
+		`{"level":"error","ts":1523537728,"caller":"logger/logger.go:12","msg":"failed","error":"boom","errorVerbose":"boom\nmain.run\n\tmain.go:10","stacktrace":"main.run\n\tmain.go:10\nmain.main\n\tmain.go:5"}`,
+	}
+
+	var got strings.Builder
+	for _, input := range inputs {
+		tr := &testReader{}
+		_, err := PrettyConsole{Sink: tr, Color: ColorNever}.Write([]byte(input))
+		require.NoError(t, err)
+		got.WriteString(tr.Written)
+	}
+
+	golden := filepath.Join("testdata", "prettyconsole.golden")
+	if *update {
+		require.NoError(t, ioutil.WriteFile(golden, []byte(got.String()), 0644))
+	}
+	want, err := ioutil.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), got.String())
+}
+
+func TestPrettyConsole_TraceIDHighlighted(t *testing.T) {
+	tr := &testReader{}
+	_, err := PrettyConsole{Sink: tr, Color: ColorAlways}.Write([]byte(`{"ts":1523537728, "level":"info", "msg":"m", "a":"b", "TraceID":"abc"}`))
+	require.NoError(t, err)
+	assert.Contains(t, tr.Written, "\x1b[35mTraceID\x1b[0m=\x1b[35mabc\x1b[0m \x1b[32ma\x1b[0m=b ")
+}
+
+func TestPrettyConsole_Fields(t *testing.T) {
+	input := []byte(`{"ts":1523537728, "level":"info", "msg":"m", "b":"2", "error":"boom", "a":"1", "request_id":"r1", "TraceID":"abc"}`)
+	tests := []struct {
+		name    string
+		fields  []string
+		verbose bool
+		want    string
+	}{
+		{"all", nil, false, "TraceID=abc a=1 b=2 error=boom request_id=r1 "},
+		{"pinned", []string{"request_id", "error", "missing"}, false, "request_id=r1 error=boom "},
+		{"pinned and verbose", []string{"request_id", "error", "request_id"}, true, "request_id=r1 error=boom TraceID=abc a=1 b=2 "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &testReader{}
+			pc := PrettyConsole{Sink: tr, Color: ColorNever, Fields: tt.fields, Verbose: tt.verbose}
+			_, err := pc.Write(input)
+			require.NoError(t, err)
+			assert.True(t, strings.HasSuffix(tr.Written, " "+tt.want+"\n"), tr.Written)
+		})
+	}
+}
+
+func TestPrettyConsole_MaxDepth(t *testing.T) {
+	input := []byte(`{"ts":1523537728, "level":"info", "msg":"m", "cfg":{"a":1, "b":[1, {"c":{"d":"e"}}]}, "n":1}`)
+	tests := []struct {
+		maxDepth int
+		want     string
+	}{
+		{0, `cfg={"a":1,"b":[1,{"c":{"d":"e"}}]} n=1 `},
+		{1, `cfg={"a":1,"b":[…]} n=1 `},
+		{3, `cfg={"a":1,"b":[1,{"c":{…}}]} n=1 `},
+	}
+
+	for _, tt := range tests {
+		tr := &testReader{}
+		_, err := PrettyConsole{Sink: tr, Color: ColorNever, MaxDepth: tt.maxDepth}.Write(input)
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(tr.Written, " "+tt.want+"\n"), tr.Written)
+	}
+}
+
+func TestPrettyConsole_Truncation(t *testing.T) {
+	input := []byte(`{"ts":1523537728, "level":"info", "msg":"a message too long", "short":"abc", "long":"abcdefghij", "stack":"line one\nline two is long"}`)
+
+	tr := &testReader{}
+	_, err := PrettyConsole{Sink: tr, Color: ColorNever, MaxValueWidth: 5, MaxMessageWidth: 9}.Write(input)
+	require.NoError(t, err)
+	assert.Equal(t, "2018-04-12T12:55:28.000Z [INFO]  a messag…                                           long=abcd… short=abc \n"+
+		"stack:\n\tline one\n\tline two is long\n", tr.Written)
+
+	tr = &testReader{}
+	_, err = PrettyConsole{Sink: tr, Color: ColorNever}.Write(input)
+	require.NoError(t, err)
+	assert.Contains(t, tr.Written, "a message too long")
+	assert.Contains(t, tr.Written, "long=abcdefghij")
+}
+
+func TestPrettyConsoleSink(t *testing.T) {
+	u, err := url.Parse("pretty://stdout?fields=request_id,error&verbose=true")
+	require.NoError(t, err)
+	sink, err := prettyConsoleSink(u)
+	require.NoError(t, err)
+	pc := sink.(PrettyConsole)
+	assert.Equal(t, consoleSink{os.Stdout}, pc.Sink)
+	assert.Equal(t, []string{"request_id", "error"}, pc.Fields)
+	assert.True(t, pc.Verbose)
+
+	u, err = url.Parse("pretty://console?verbose=sometimes")
+	require.NoError(t, err)
+	_, err = prettyConsoleSink(u)
+	assert.EqualError(t, err, `invalid verbose for pretty output: strconv.ParseBool: parsing "sometimes": invalid syntax`)
+}
+
+func TestPrettyConsole_Timestamps(t *testing.T) {
+	tests := []struct {
+		name   string
+		ts     string
+		layout string
+		want   string
+	}{
+		{"epoch", `1523537728.7260377`, "", "2018-04-12T12:55:28.726Z"},
+		{"millis", `1523537728726.0377`, "", "2018-04-12T12:55:28.726Z"},
+		{"nanos", `1523537728726037700`, "", "2018-04-12T12:55:28.726Z"},
+		{"rfc3339", `"2018-04-12T12:55:28Z"`, "", "2018-04-12T12:55:28.000Z"},
+		{"rfc3339nano", `"2018-04-12T14:55:28.7260377+02:00"`, "", "2018-04-12T12:55:28.726Z"},
+		{"layout", `1523537728.7260377`, time.Kitchen, "12:55PM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &testReader{}
+			pc := PrettyConsole{Sink: tr, TimeLayout: tt.layout}
+			_, err := pc.Write([]byte(`{"ts":` + tt.ts + `, "level":"info", "msg":"m"}`))
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(tr.Written, tt.want+" "), tr.Written)
+		})
+	}
+}
+
+func TestPrettyConsole_Color(t *testing.T) {
+	input := []byte(`{"ts":1523537728, "level":"info", "msg":"top level", "details":"nuances"}`)
+	uncolored := "2018-04-12T12:55:28.000Z [INFO]  top level                                           details=nuances \n"
+
+	t.Run("never", func(t *testing.T) {
+		tr := &testReader{}
+		_, err := PrettyConsole{Sink: tr, Color: ColorNever}.Write(input)
+		require.NoError(t, err)
+		assert.Equal(t, uncolored, tr.Written)
+	})
+
+	t.Run("auto without a terminal", func(t *testing.T) {
+		tr := &testReader{}
+		_, err := PrettyConsole{Sink: tr}.Write(input)
+		require.NoError(t, err)
+		assert.Equal(t, uncolored, tr.Written)
+	})
+
+	t.Run("custom level names", func(t *testing.T) {
+		tr := &testReader{}
+		pc := PrettyConsole{Sink: tr, Color: ColorAlways}
+		_, err := pc.Write([]byte(`{"ts":1523537728, "level":"WARNING", "msg":"top level"}`))
+		require.NoError(t, err)
+		assert.Contains(t, tr.Written, levelColors["warn"]("[WARNING]"))
+	})
+
+	t.Run("NO_COLOR", func(t *testing.T) {
+		require.NoError(t, os.Setenv("NO_COLOR", "1"))
+		defer os.Unsetenv("NO_COLOR")
+		pc := PrettyConsole{Sink: &testFdReader{fd: os.Stdout.Fd()}}
+		assert.False(t, pc.colored())
+	})
+}
+
+func TestSetLevelColors(t *testing.T) {
+	defer SetLevelColors(nil)
+	SetLevelColors(map[zapcore.Level]string{
+		zapcore.DebugLevel: "1;34",
+		zapcore.WarnLevel:  "35",
+		zapcore.ErrorLevel: "not a color",
+	})
+
+	write := func(level string) string {
+		tr := &testReader{}
+		_, err := PrettyConsole{Sink: tr, Color: ColorAlways}.Write([]byte(`{"ts":1523537728, "level":"` + level + `", "msg":"m"}`))
+		require.NoError(t, err)
+		return tr.Written
+	}
+	assert.Contains(t, write("debug"), "\x1b[1;34m[DEBUG] \x1b[0m")
+	assert.Contains(t, write("WARNING"), "\x1b[35m[WARNING]\x1b[0m")
+	assert.Contains(t, write("error"), levelColors["error"]("[ERROR] "), "invalid colors are ignored")
+	assert.Contains(t, write("info"), levelColors["info"]("[INFO]  "), "other levels keep their color")
+
+	tr := &testReader{}
+	_, err := PrettyConsole{Sink: tr, Color: ColorNever}.Write([]byte(`{"ts":1523537728, "level":"debug", "msg":"m"}`))
+	require.NoError(t, err)
+	assert.NotContains(t, tr.Written, "\x1b[")
+
+	SetLevelColors(nil)
+	assert.Contains(t, write("debug"), levelColors["debug"]("[DEBUG] "))
+}
+
 type testReader struct {
 	Written string
 }
@@ -63,3 +274,10 @@ func (tr *testReader) Write(b []byte) (int, error) {
 	tr.Written = string(b)
 	return 0, nil
 }
+
+type testFdReader struct {
+	testReader
+	fd uintptr
+}
+
+func (tr *testFdReader) Fd() uintptr { return tr.fd }
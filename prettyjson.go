@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+var yellow = newColor(color.FgYellow)
+
+// PrettyJSON wraps a Sink, usually stdout, and writes the incoming json
+// entries indented, with their keys and values colored by type, for
+// inspecting large payloads. Unlike PrettyConsole, it keeps every field as
+// it was encoded. When the output is not colored, by Color, entries are
+// written as compact json instead, one per line, as they are meant for
+// another program rather than a person.
+type PrettyJSON struct {
+	zap.Sink
+	// Color controls whether the output is indented and colored, ColorAuto
+	// by default.
+	Color ColorMode
+}
+
+// Write reformats the incoming json bytes as indented, colored, json.
+func (pj PrettyJSON) Write(b []byte) (int, error) {
+	if !gjson.ValidBytes(b) {
+		return 0, fmt.Errorf("unable to parse json for pretty json: %s", string(b))
+	}
+	var out bytes.Buffer
+	if colored(pj.Color, pj.Sink) {
+		writePrettyJSON(&out, gjson.ParseBytes(b), "", "")
+	} else if err := json.Compact(&out, b); err != nil {
+		return 0, err
+	}
+	out.WriteByte('\n')
+	return pj.Sink.Write(out.Bytes())
+}
+
+// writePrettyJSON writes v at the given indentation, with the level of the
+// entry in the color of its level. key is the key of v in its parent.
+func writePrettyJSON(out *bytes.Buffer, v gjson.Result, indent, key string) {
+	switch {
+	case v.IsObject(), v.IsArray():
+		open, end := "[", "]"
+		if v.IsObject() {
+			open, end = "{", "}"
+		}
+		out.WriteString(open)
+		first := true
+		v.ForEach(func(k, value gjson.Result) bool {
+			if !first {
+				out.WriteByte(',')
+			}
+			first = false
+			out.WriteString("\n" + indent + "  ")
+			if k.Exists() {
+				out.WriteString(blue(k.Raw) + ": ")
+			}
+			writePrettyJSON(out, value, indent+"  ", k.String())
+			return true
+		})
+		if !first {
+			out.WriteString("\n" + indent)
+		}
+		out.WriteString(end)
+	case v.Type == gjson.String:
+		if key == "level" && indent == "  " {
+			if color, ok := levelColor(v.String()); ok {
+				out.WriteString(color(v.Raw))
+				return
+			}
+		}
+		out.WriteString(green(v.Raw))
+	case v.Type == gjson.Number:
+		out.WriteString(yellow(v.Raw))
+	default:
+		out.WriteString(magenta(v.Raw))
+	}
+}
+
+// prettyJSONSink opens a PrettyJSON for the prettyjson://stdout output path
+// on stdout, and for any other prettyjson:// path on stderr.
+func prettyJSONSink(u *url.URL) (zap.Sink, error) {
+	if u.Host == "stdout" {
+		return PrettyJSON{Sink: consoleSink{os.Stdout}}, nil
+	}
+	return PrettyJSON{Sink: consoleSink{os.Stderr}}, nil
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrettyJSON_Write(t *testing.T) {
+	input := []byte(`{"level":"warn", "msg":"m", "n":1.5, "ok":true, "empty":{}, "payload":{"ids":[1,"two",null]}}` + "\n")
+
+	t.Run("colored", func(t *testing.T) {
+		tr := &testReader{}
+		_, err := PrettyJSON{Sink: tr, Color: ColorAlways}.Write(input)
+		require.NoError(t, err)
+		assert.Equal(t, "{\n"+
+			"  "+blue(`"level"`)+": "+levelColors["warn"](`"warn"`)+",\n"+
+			"  "+blue(`"msg"`)+": "+green(`"m"`)+",\n"+
+			"  "+blue(`"n"`)+": "+yellow("1.5")+",\n"+
+			"  "+blue(`"ok"`)+": "+magenta("true")+",\n"+
+			"  "+blue(`"empty"`)+": {},\n"+
+			"  "+blue(`"payload"`)+": {\n"+
+			"    "+blue(`"ids"`)+": [\n"+
+			"      "+yellow("1")+",\n"+
+			"      "+green(`"two"`)+",\n"+
+			"      "+magenta("null")+"\n"+
+			"    ]\n"+
+			"  }\n"+
+			"}\n", tr.Written)
+	})
+
+	t.Run("uncolored", func(t *testing.T) {
+		tr := &testReader{}
+		_, err := PrettyJSON{Sink: tr}.Write(input)
+		require.NoError(t, err)
+		assert.Equal(t, `{"level":"warn","msg":"m","n":1.5,"ok":true,"empty":{},"payload":{"ids":[1,"two",null]}}`+"\n", tr.Written)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := PrettyJSON{Sink: &testReader{}}.Write([]byte("{"))
+		assert.Error(t, err)
+	})
+}
+
+func TestPrettyJSONSink(t *testing.T) {
+	u, err := url.Parse("prettyjson://stdout")
+	require.NoError(t, err)
+	sink, err := prettyJSONSink(u)
+	require.NoError(t, err)
+	assert.Equal(t, PrettyJSON{Sink: consoleSink{os.Stdout}}, sink)
+
+	u, err = url.Parse("prettyjson://console")
+	require.NoError(t, err)
+	sink, err = prettyJSONSink(u)
+	require.NoError(t, err)
+	assert.Equal(t, PrettyJSON{Sink: consoleSink{os.Stderr}}, sink)
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewRateLimitedLogger returns a child of l that writes at most one entry
+// with a given message every perKey, whatever its level or fields, and drops
+// the others. Unlike NewSampledLogger, nothing gets through a flood of one
+// message until perKey has passed since the last entry written.
+//
+// Dropped entries are counted by the log_lines_dropped_total counter, in
+// addition to log_lines_total.
+func NewRateLimitedLogger(l *Logger, perKey time.Duration) *Logger {
+	limiter := &rateLimiter{perKey: perKey, last: map[string]time.Time{}}
+	zl := l.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &rateLimitedCore{Core: core, limiter: limiter}
+	}))
+	return NewLogger(zl)
+}
+
+type rateLimiter struct {
+	perKey time.Duration
+	mu     sync.Mutex
+	// last is when an entry with each message was last written.
+	last map[string]time.Time
+}
+
+// allow reports whether an entry with msg logged at t may be written,
+// recording t as its last write if so.
+func (r *rateLimiter) allow(msg string, t time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if last, ok := r.last[msg]; ok && t.Sub(last) < r.perKey {
+		return false
+	}
+	r.last[msg] = t
+	// Forget the messages that are no longer limited, so that logging many
+	// distinct messages does not grow the map forever.
+	if len(r.last) > rateLimiterSweepSize {
+		for m, last := range r.last {
+			if t.Sub(last) >= r.perKey {
+				delete(r.last, m)
+			}
+		}
+	}
+	return true
+}
+
+// rateLimiterSweepSize is the number of tracked messages above which those
+// no longer limited are forgotten.
+const rateLimiterSweepSize = 1024
+
+type rateLimitedCore struct {
+	zapcore.Core
+	limiter *rateLimiter
+}
+
+func (c *rateLimitedCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitedCore{Core: c.Core.With(fields), limiter: c.limiter}
+}
+
+func (c *rateLimitedCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return ce
+	}
+	if !c.limiter.allow(entry.Message, entry.Time) {
+		getCounters().droppedLines.WithLabelValues(levelName(entry.Level)).Inc()
+		return ce
+	}
+	return c.Core.Check(entry, ce)
+}
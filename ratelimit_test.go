@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewRateLimitedLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewRateLimitedLogger(NewLogger(zap.New(core)), time.Hour)
+	dropped := getCounters().droppedLines.WithLabelValues(zapcore.WarnLevel.String())
+
+	before := testutil.ToFloat64(dropped)
+	for i := 0; i < 5; i++ {
+		l.Warnw("loop", "i", i)
+	}
+	l.Errorw("loop")
+	l.Warnw("other")
+
+	assert.Equal(t, before+4, testutil.ToFloat64(dropped))
+	assert.Equal(t, 1, logs.FilterMessage("loop").Len(), "the key is the message, whatever the level")
+	assert.Equal(t, 1, logs.FilterMessage("other").Len())
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	r := &rateLimiter{perKey: time.Second, last: map[string]time.Time{}}
+	now := time.Now()
+
+	assert.True(t, r.allow("msg", now))
+	assert.False(t, r.allow("msg", now.Add(999*time.Millisecond)))
+	assert.True(t, r.allow("msg", now.Add(time.Second)))
+	assert.False(t, r.allow("msg", now.Add(1500*time.Millisecond)), "the window restarts at the last write")
+}
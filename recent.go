@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// recentLogs keeps the entries written to the recent:// outputs.
+var recentLogs = &ringBuffer{}
+
+// WithRecentLogs keeps the last size entries written by the logger in
+// memory, whichever of its outputs they are routed to, for RecentLogs to
+// return. It is the recent://?size=N output path, which can also be listed
+// in a config file.
+func WithRecentLogs(size int) Option {
+	return func(config *zap.Config) {
+		config.OutputPaths = append(config.OutputPaths, recentURI(size))
+	}
+}
+
+// RecentLogs returns the entries last written by the loggers with a
+// recent:// output, the oldest first, as they were encoded. It is safe to
+// call while logging.
+func RecentLogs() []string {
+	return recentLogs.lines()
+}
+
+func recentURI(size int) string {
+	u := url.URL{
+		Scheme:   "recent",
+		RawQuery: url.Values{"size": {strconv.Itoa(size)}}.Encode(),
+	}
+	return u.String()
+}
+
+// isRecentPath reports whether path is a recent:// output path.
+func isRecentPath(path string) bool {
+	return strings.HasPrefix(path, "recent:")
+}
+
+// newRecentSink opens the recent:// output as the shared ring buffer,
+// resized to the size query parameter.
+func newRecentSink(u *url.URL) (zap.Sink, error) {
+	size, err := strconv.Atoi(u.Query().Get("size"))
+	if err != nil || size <= 0 {
+		return nil, errors.Errorf("invalid size for recent output: %q is not a positive integer", u.Query().Get("size"))
+	}
+	recentLogs.resize(size)
+	return recentLogs, nil
+}
+
+// ringBuffer is a zap.Sink keeping the last lines written to it.
+type ringBuffer struct {
+	mu sync.Mutex
+	// buf holds the lines, the oldest at next once it is full.
+	buf  []string
+	next int
+	full bool
+}
+
+// resize empties the buffer unless it already has the given size.
+func (rb *ringBuffer) resize(size int) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if len(rb.buf) != size {
+		rb.buf, rb.next, rb.full = make([]string, size), 0, false
+	}
+}
+
+func (rb *ringBuffer) Write(b []byte) (int, error) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if len(rb.buf) == 0 {
+		return len(b), nil
+	}
+	rb.buf[rb.next] = strings.TrimSuffix(string(b), "\n")
+	rb.next = (rb.next + 1) % len(rb.buf)
+	rb.full = rb.full || rb.next == 0
+	return len(b), nil
+}
+
+func (rb *ringBuffer) lines() []string {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if !rb.full {
+		return append([]string{}, rb.buf[:rb.next]...)
+	}
+	return append(append([]string{}, rb.buf[rb.next:]...), rb.buf[:rb.next]...)
+}
+
+func (*ringBuffer) Sync() error  { return nil }
+func (*ringBuffer) Close() error { return nil }
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRingBuffer(t *testing.T) {
+	rb := &ringBuffer{}
+	rb.resize(3)
+	assert.Empty(t, rb.lines())
+
+	for i := 1; i <= 2; i++ {
+		_, _ = rb.Write([]byte(fmt.Sprintf("line %d\n", i)))
+	}
+	assert.Equal(t, []string{"line 1", "line 2"}, rb.lines())
+
+	for i := 3; i <= 5; i++ {
+		_, _ = rb.Write([]byte(fmt.Sprintf("line %d\n", i)))
+	}
+	assert.Equal(t, []string{"line 3", "line 4", "line 5"}, rb.lines())
+
+	rb.resize(3)
+	assert.Len(t, rb.lines(), 3, "resizing to the same size should keep the lines")
+	rb.resize(2)
+	assert.Empty(t, rb.lines())
+}
+
+func TestWithRecentLogs(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	defer recentLogs.resize(0)
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l, err := NewSplitLevelLogger(filepath.Join(dir, "low.jsonl"), filepath.Join(dir, "high.jsonl"), zapcore.InfoLevel, WithRecentLogs(2))
+	require.NoError(t, err)
+	defer l.Close()
+
+	l.Debugw("disabled")
+	l.Infow("low", "key", "value")
+	l.Warnw("high")
+	recent := RecentLogs()
+	require.Len(t, recent, 2)
+	assert.Contains(t, recent[0], `"msg":"low","key":"value"`)
+	assert.Contains(t, recent[1], `"msg":"high"`)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Infow("concurrent")
+				assert.Len(t, RecentLogs(), 2)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewRecentSink_Invalid(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	config := productionConfig(true, zapcore.InfoLevel)
+	config.OutputPaths = []string{"recent://?size=0"}
+	_, err := buildLogger(config)
+	assert.EqualError(t, err, `couldn't open sink "recent://?size=0": invalid size for recent output: "0" is not a positive integer`)
+}
@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+)
+
+// RecoverAndLog recovers a panic of the calling goroutine, logs it at the
+// error level with the stack of the panic, syncs l so that no buffered entry
+// is lost, and panics again with the same value. It must be deferred
+// directly, as in defer RecoverAndLog(l), to recover anything.
+func RecoverAndLog(l *Logger) {
+	if r := recover(); r != nil {
+		logPanic(l, r)
+		panic(r)
+	}
+}
+
+// RecoverAndLogWithoutRepanic is like RecoverAndLog, but lets the goroutine
+// carry on after logging the panic, for workers that outlive the failure of
+// a task.
+func RecoverAndLogWithoutRepanic(l *Logger) {
+	if r := recover(); r != nil {
+		logPanic(l, r)
+	}
+}
+
+func logPanic(l *Logger, r interface{}) {
+	// Skip logPanic and the Recover function; the panicking frames follow.
+	l.Errorw("recovered from panic", "panic", r, zap.StackSkip("stack", 2))
+	_ = l.Sync()
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// syncRecorder is a bytes.Buffer recording whether it was synced.
+type syncRecorder struct {
+	bytes.Buffer
+	synced bool
+}
+
+func (r *syncRecorder) Sync() error {
+	r.synced = true
+	return nil
+}
+
+func panicking() {
+	panic("worker failed")
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	var out syncRecorder
+	l := NewWriterLogger(&out, zapcore.InfoLevel, true)
+
+	assert.PanicsWithValue(t, "worker failed", func() {
+		defer RecoverAndLog(l)
+		panicking()
+	})
+	assert.True(t, out.synced)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, "error", entry["level"])
+	assert.Equal(t, "recovered from panic", entry["msg"])
+	assert.Equal(t, "worker failed", entry["panic"])
+	assert.Contains(t, entry["stack"], "logger.panicking")
+}
+
+func TestRecoverAndLogWithoutRepanic(t *testing.T) {
+	var out syncRecorder
+	l := NewWriterLogger(&out, zapcore.InfoLevel, true)
+
+	assert.NotPanics(t, func() {
+		defer RecoverAndLogWithoutRepanic(l)
+		panicking()
+	})
+	assert.True(t, out.synced)
+	assert.Contains(t, out.String(), `"panic":"worker failed"`)
+
+	out.Reset()
+	out.synced = false
+	func() {
+		defer RecoverAndLogWithoutRepanic(l)
+	}()
+	assert.Zero(t, out.Len())
+	assert.False(t, out.synced)
+}
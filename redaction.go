@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// redactedValue replaces the values of redacted keys.
+const redactedValue = "[REDACTED]"
+
+var (
+	redactedKeysMu sync.RWMutex
+	// redactedKeys holds the lowercased keys registered for redaction.
+	redactedKeys = map[string]struct{}{}
+)
+
+// RegisterRedactedKeys adds keys whose values are replaced with [REDACTED]
+// when passed as key value pairs to the ...w methods, or to With. Keys are
+// matched case-insensitively.
+func RegisterRedactedKeys(keys ...string) {
+	redactedKeysMu.Lock()
+	defer redactedKeysMu.Unlock()
+	for _, k := range keys {
+		redactedKeys[strings.ToLower(k)] = struct{}{}
+	}
+}
+
+// IsRedactedKey reports whether key was registered with
+// RegisterRedactedKeys, for the values logged other than as key value pairs,
+// such as HTTP headers, to be redacted too.
+func IsRedactedKey(key string) bool {
+	redactedKeysMu.RLock()
+	defer redactedKeysMu.RUnlock()
+	return isRedactedKey(key)
+}
+
+func isRedactedKey(key string) bool {
+	_, ok := redactedKeys[strings.ToLower(key)]
+	return ok
+}
+
+// redact returns keysAndValues with the values of registered keys masked.
+// The given slice is copied rather than modified when anything is masked.
+func redact(keysAndValues []interface{}) []interface{} {
+	redactedKeysMu.RLock()
+	defer redactedKeysMu.RUnlock()
+	if len(redactedKeys) == 0 {
+		return keysAndValues
+	}
+
+	var redacted []interface{}
+	mask := func(i int, v interface{}) {
+		if redacted == nil {
+			redacted = append([]interface{}(nil), keysAndValues...)
+		}
+		redacted[i] = v
+	}
+	for i := 0; i < len(keysAndValues); i++ {
+		switch k := keysAndValues[i].(type) {
+		case zap.Field:
+			// Strongly typed fields stand on their own, without a value.
+			if isRedactedKey(k.Key) {
+				mask(i, zap.String(k.Key, redactedValue))
+			}
+		case string:
+			if i+1 < len(keysAndValues) && isRedactedKey(k) {
+				mask(i+1, redactedValue)
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	if redacted == nil {
+		return keysAndValues
+	}
+	return redacted
+}
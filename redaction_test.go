@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRegisterRedactedKeys(t *testing.T) {
+	defer func() { redactedKeys = map[string]struct{}{} }()
+	RegisterRedactedKeys("password", "Private_Key")
+	assert.True(t, IsRedactedKey("private_key"))
+	assert.False(t, IsRedactedKey("user"))
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	SetLogger(zap.New(core))
+
+	kv := []interface{}{"user", "bob", "PASSWORD", "hunter2"}
+	Infow("package", kv...)
+	GetLogger().With("private_key", "0x01").Errorw("instance", zap.String("Password", "hunter2"), "count", 1)
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Equal(t, map[string]interface{}{"user": "bob", "PASSWORD": redactedValue}, entries[0].ContextMap())
+	assert.Equal(t, map[string]interface{}{"private_key": redactedValue, "Password": redactedValue, "count": int64(1)}, entries[1].ContextMap())
+	assert.Equal(t, "hunter2", kv[3], "the caller's slice must not be modified")
+}
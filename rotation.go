@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// DiskLogConfig configures the rotation of the log file written to disk.
+// A zero value for any field disables that limit.
+type DiskLogConfig struct {
+	// MaxSizeMB is the size in megabytes the log file may reach before it
+	// is rotated.
+	MaxSizeMB int `json:"maxSizeMB" yaml:"maxSizeMB"`
+	// MaxBackups is the number of rotated log files to keep.
+	MaxBackups int `json:"maxBackups" yaml:"maxBackups"`
+	// MaxAgeDays is the number of days to keep rotated log files.
+	MaxAgeDays int `json:"maxAgeDays" yaml:"maxAgeDays"`
+}
+
+// NewRotatingProductionLogger returns a Logger like NewProductionLogger with
+// toDisk set, except the log file in dir is rotated according to disk.
+func NewRotatingProductionLogger(
+	dir string, jsonConsole bool, lvl zapcore.Level, disk DiskLogConfig, opts ...Option) (*Logger, error) {
+	config := productionConfig(jsonConsole, lvl)
+	destination, err := rotatingLogFileURI(dir, disk)
+	if err != nil {
+		return nil, err
+	}
+	config.OutputPaths = append(config.OutputPaths, destination)
+	config.ErrorOutputPaths = append(config.ErrorOutputPaths, destination)
+	applyOptions(&config, opts)
+	return buildLogger(config)
+}
+
+// rotatingLogFileURI returns a rotate:/// URI for the log file in the passed
+// directory, carrying the rotation limits as query parameters.
+func rotatingLogFileURI(configRootDir string, disk DiskLogConfig) (string, error) {
+	path, err := filepath.Abs(filepath.Join(configRootDir, "log.jsonl"))
+	if err != nil {
+		return "", err
+	}
+	u := url.URL{
+		Scheme: "rotate",
+		// Windows paths need a leading slash to be parsed as a path.
+		Path: "/" + strings.TrimPrefix(filepath.ToSlash(path), "/"),
+		RawQuery: url.Values{
+			"maxSize":    {strconv.Itoa(disk.MaxSizeMB)},
+			"maxBackups": {strconv.Itoa(disk.MaxBackups)},
+			"maxAge":     {strconv.Itoa(disk.MaxAgeDays)},
+		}.Encode(),
+	}
+	return u.String(), nil
+}
+
+var (
+	rotatingFilesMu sync.Mutex
+	// rotatingFiles holds the files opened by rotatingSinks, by URI, shared
+	// by the sinks of the same URI, such as the output and the error output
+	// of a logger, so that the file is rotated once for all of them.
+	rotatingFiles = map[string]*rotatingFile{}
+)
+
+// rotatingFile is a lumberjack.Logger shared by several rotatingSinks.
+type rotatingFile struct {
+	*lumberjack.Logger
+	refs int
+}
+
+// rotatingSink adapts a lumberjack.Logger to a zap.Sink. lumberjack writes
+// straight to the file, so there is nothing to sync.
+type rotatingSink struct {
+	*lumberjack.Logger
+	uri    string
+	closed bool
+}
+
+func (*rotatingSink) Sync() error { return nil }
+
+// Close closes the file once every sink sharing it is closed.
+func (s *rotatingSink) Close() error {
+	rotatingFilesMu.Lock()
+	defer rotatingFilesMu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	f := rotatingFiles[s.uri]
+	if f.refs--; f.refs > 0 {
+		return nil
+	}
+	delete(rotatingFiles, s.uri)
+	return f.Close()
+}
+
+func newRotatingSink(u *url.URL) (zap.Sink, error) {
+	query := u.Query()
+	limit := func(key string) (int, error) {
+		v := query.Get(key)
+		if v == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(v)
+		return n, errors.Wrapf(err, "invalid %s for rotating log file", key)
+	}
+
+	maxSize, err := limit("maxSize")
+	if err != nil {
+		return nil, err
+	}
+	maxBackups, err := limit("maxBackups")
+	if err != nil {
+		return nil, err
+	}
+	maxAge, err := limit("maxAge")
+	if err != nil {
+		return nil, err
+	}
+
+	rotatingFilesMu.Lock()
+	defer rotatingFilesMu.Unlock()
+	uri := u.String()
+	f, ok := rotatingFiles[uri]
+	if !ok {
+		f = &rotatingFile{Logger: &lumberjack.Logger{
+			Filename:   filePathFromURI(u),
+			MaxSize:    maxSize,
+			MaxBackups: maxBackups,
+			MaxAge:     maxAge,
+		}}
+		rotatingFiles[uri] = f
+	}
+	f.refs++
+	return &rotatingSink{Logger: f.Logger, uri: uri}, nil
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRotatingSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	uri, err := rotatingLogFileURI(dir, DiskLogConfig{MaxSizeMB: 1, MaxBackups: 1})
+	require.NoError(t, err)
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	sink, err := newRotatingSink(u)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	line := []byte(strings.Repeat("x", 1023) + "\n")
+	for i := 0; i < 1100; i++ {
+		_, err = sink.Write(line)
+		require.NoError(t, err)
+	}
+	require.NoError(t, sink.Sync())
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "expected the log file and one rotated backup")
+}
+
+func TestNewRotatingSink_InvalidLimit(t *testing.T) {
+	u, err := url.Parse("rotate:///tmp/log.jsonl?maxSize=big")
+	require.NoError(t, err)
+
+	_, err = newRotatingSink(u)
+	assert.EqualError(t, err, `invalid maxSize for rotating log file: strconv.Atoi: parsing "big": invalid syntax`)
+}
+
+func TestRotatingSink_Shared(t *testing.T) {
+	u, err := url.Parse("rotate:///tmp/shared.jsonl?maxSize=1")
+	require.NoError(t, err)
+	first, err := newRotatingSink(u)
+	require.NoError(t, err)
+	second, err := newRotatingSink(u)
+	require.NoError(t, err)
+	assert.Same(t, first.(*rotatingSink).Logger, second.(*rotatingSink).Logger)
+
+	require.NoError(t, first.Close())
+	require.NoError(t, first.Close())
+	assert.Contains(t, rotatingFiles, u.String(), "the file should stay open for the second sink")
+	require.NoError(t, second.Close())
+	assert.NotContains(t, rotatingFiles, u.String())
+}
+
+func TestNewRotatingProductionLogger_ErrorOutput(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	defer func() { entryHooks = nil }()
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	RegisterHook(func(zapcore.Entry) error { return errors.New("broken hook") })
+
+	l, err := NewRotatingProductionLogger(dir, true, zapcore.InfoLevel, DiskLogConfig{MaxSizeMB: 1})
+	require.NoError(t, err)
+	l.Info("hooked")
+	require.NoError(t, l.Close())
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "log.jsonl"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"msg":"hooked"`)
+	assert.Contains(t, string(b), "broken hook", "the errors of the logger should be written to the file too")
+}
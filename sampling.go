@@ -0,0 +1,243 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// samplingTick is the interval sampling decisions are made over.
+const samplingTick = time.Second
+
+// NewSampledLogger returns a child of l that, every second, logs the first
+// initial entries with a given level and message, and every thereafter-th
+// entry after that. Entries with different levels or messages are sampled
+// independently, so a flood of one message never starves another.
+//
+// The log_lines_total counter counts entries before sampling, since it is
+// incremented by every call, while log_bytes_total only counts the entries
+// actually written.
+func NewSampledLogger(l *Logger, initial, thereafter int) *Logger {
+	zl := l.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newSampler(core, samplingTick, initial, thereafter)
+	}))
+	return l.child(zl.Sugar())
+}
+
+// SamplingPolicy is the sampling of the entries of one level: every second,
+// the first Initial entries with a given message are logged, then every
+// Thereafter-th one, as for NewSampledLogger, or none if Thereafter is 0.
+type SamplingPolicy struct {
+	Initial    int
+	Thereafter int
+}
+
+// NewLevelSampledLogger returns a child of l sampling the entries of each
+// level of policies with its policy, such as the info and debug levels, and
+// leaving the entries of the other levels, such as the error level, all
+// logged. As with NewSampledLogger, trace entries are never sampled.
+//
+// The log_lines_total counter counts entries before sampling, since it is
+// incremented by every call, while log_bytes_total only counts the entries
+// actually written. The entries sampled out are counted by the
+// log_lines_dropped_total counter.
+func NewLevelSampledLogger(l *Logger, policies map[zapcore.Level]SamplingPolicy) *Logger {
+	zl := l.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		samplers := make(map[zapcore.Level]zapcore.Core, len(policies))
+		for lvl, p := range policies {
+			thereafter := p.Thereafter
+			if thereafter <= 0 {
+				// The zap sampler divides by thereafter, and never gets
+				// this far in a tick.
+				thereafter = math.MaxInt32
+			}
+			samplers[lvl] = newSampler(core, samplingTick, p.Initial, thereafter, zapcore.SamplerHook(countDropped))
+		}
+		return &levelSampledCore{Core: core, samplers: samplers}
+	}))
+	return l.child(zl.Sugar())
+}
+
+// countDropped is a sampler hook counting the entries sampled out.
+func countDropped(entry zapcore.Entry, dec zapcore.SamplingDecision) {
+	if dec&zapcore.LogDropped != 0 {
+		getCounters().droppedLines.WithLabelValues(levelName(entry.Level)).Inc()
+	}
+}
+
+// levelSampledCore writes the entries of the levels of samplers through
+// their sampler, and the others directly to the core it wraps.
+type levelSampledCore struct {
+	zapcore.Core
+	samplers map[zapcore.Level]zapcore.Core
+}
+
+func (c *levelSampledCore) With(fields []zapcore.Field) zapcore.Core {
+	samplers := make(map[zapcore.Level]zapcore.Core, len(c.samplers))
+	for lvl, s := range c.samplers {
+		samplers[lvl] = s.With(fields)
+	}
+	return &levelSampledCore{Core: c.Core.With(fields), samplers: samplers}
+}
+
+func (c *levelSampledCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if s, ok := c.samplers[entry.Level]; ok {
+		return s.Check(entry, ce)
+	}
+	return c.Core.Check(entry, ce)
+}
+
+// newSampler is zapcore.NewSamplerWithOptions, except that entries below
+// the debug level, which the zap sampler can't count, are not sampled.
+func newSampler(core zapcore.Core, tick time.Duration, first, thereafter int, opts ...zapcore.SamplerOption) zapcore.Core {
+	sample := func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, tick, first, thereafter, opts...)
+	}
+	return &traceUnsampledCore{Core: sample(core), unsampled: core, sample: sample}
+}
+
+// traceUnsampledCore is a sampler letting trace entries through to the
+// core it samples.
+type traceUnsampledCore struct {
+	zapcore.Core
+	unsampled zapcore.Core
+	// sample returns a new zap sampler of the given core, with the options
+	// of the one c wraps.
+	sample func(zapcore.Core) zapcore.Core
+}
+
+func (c *traceUnsampledCore) With(fields []zapcore.Field) zapcore.Core {
+	return &traceUnsampledCore{Core: c.Core.With(fields), unsampled: c.unsampled.With(fields), sample: c.sample}
+}
+
+func (c *traceUnsampledCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level < zapcore.DebugLevel {
+		return c.unsampled.Check(entry, ce)
+	}
+	return c.Core.Check(entry, ce)
+}
+
+// NewKeySampledLogger is like NewSampledLogger, except that entries are
+// also sampled independently by the value of their key field, such as the
+// ID of the entity an error is about, whether added with With or to the
+// entry itself. Entries without the field are sampled together by level
+// and message. Since fields are only known once entries are written, the
+// decision is taken then, after the cores wrapping the sampler checked the
+// entry.
+func NewKeySampledLogger(l *Logger, key string, initial, thereafter int) *Logger {
+	state := &keySamplingState{
+		key:        key,
+		first:      initial,
+		thereafter: thereafter,
+		counts:     map[keySamplingKey]int{},
+	}
+	zl := l.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &keySampledCore{Core: core, state: state}
+	}))
+	return l.child(zl.Sugar())
+}
+
+type keySamplingKey struct {
+	level zapcore.Level
+	msg   string
+	value string
+}
+
+type keySamplingState struct {
+	key               string
+	first, thereafter int
+
+	mu sync.Mutex
+	// counts counts the entries of each key in the tick ending at end. It
+	// is cleared every tick, so that it only grows with the values seen in
+	// one.
+	counts map[keySamplingKey]int
+	end    time.Time
+}
+
+// sample reports whether an entry of key is written.
+func (s *keySamplingState) sample(key keySamplingKey, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !now.Before(s.end) {
+		s.counts = map[keySamplingKey]int{}
+		s.end = now.Truncate(samplingTick).Add(samplingTick)
+	}
+	n := s.counts[key] + 1
+	s.counts[key] = n
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+// keySampledCore samples the entries of the core it wraps by level, message
+// and value of the key field.
+type keySampledCore struct {
+	zapcore.Core
+	state *keySamplingState
+	// value is the value of the key field added with With, if any.
+	value string
+	// checked writes the entry the core was added to by Check.
+	checked *checkedCore
+}
+
+func (c *keySampledCore) With(fields []zapcore.Field) zapcore.Core {
+	value := c.value
+	if v, ok := c.state.keyValue(fields); ok {
+		value = v
+	}
+	return &keySampledCore{Core: c.Core.With(fields), state: c.state, value: value}
+}
+
+func (c *keySampledCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	// The wrapped core is checked apart, as its cores only act on the
+	// entries they checked, and written once the entry is sampled.
+	checked := c.Core.Check(entry, nil)
+	if checked == nil {
+		return ce
+	}
+	sampled := &keySampledCore{Core: c.Core, state: c.state, value: c.value}
+	ce = ce.AddCore(entry, sampled)
+	sampled.checked = &checkedCore{Core: c.Core, checked: checked, outer: ce}
+	return ce
+}
+
+// Write writes the entry through the wrapped core that checked it, if it is
+// sampled. As with the loggers of NewMultiLogger, the write errors are
+// reported once, to the error output of the logger.
+func (c *keySampledCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	value := c.value
+	if v, ok := c.state.keyValue(fields); ok {
+		value = v
+	}
+	// As with newSampler, entries below the debug level are not sampled.
+	if entry.Level >= zapcore.DebugLevel &&
+		!c.state.sample(keySamplingKey{level: entry.Level, msg: entry.Message, value: value}, entry.Time) {
+		return nil
+	}
+	return c.checked.Write(entry, fields)
+}
+
+// keyValue returns the value of the last key field of fields, as text.
+func (s *keySamplingState) keyValue(fields []zapcore.Field) (string, bool) {
+	return fieldText(fields, s.key)
+}
+
+// fieldText returns the value of the last field of fields with the given
+// key, as text.
+func fieldText(fields []zapcore.Field, key string) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key != key {
+			continue
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		fields[i].AddTo(enc)
+		return fmt.Sprint(enc.Fields[key]), true
+	}
+	return "", false
+}
@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewSampledLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewSampledLogger(NewLogger(zap.New(core)), 2, 3)
+
+	for i := 0; i < 10; i++ {
+		l.Errorw("flood", "i", i)
+	}
+	l.Errorw("rare")
+	l.Warnw("flood")
+
+	var sampled []interface{}
+	for _, entry := range logs.FilterMessage("flood").All() {
+		if entry.Level == zapcore.ErrorLevel {
+			sampled = append(sampled, entry.ContextMap()["i"])
+		}
+	}
+	assert.Equal(t, []interface{}{int64(0), int64(1), int64(4), int64(7)}, sampled)
+	assert.Equal(t, 1, logs.FilterMessage("rare").Len())
+	assert.Equal(t, 6, logs.Len(), "distinct levels and messages are sampled independently")
+}
+
+func TestNewSampledLogger_Trace(t *testing.T) {
+	core, logs := observer.New(TraceLevel)
+	l := NewSampledLogger(NewLogger(zap.New(core)), 1, 0)
+
+	for i := 0; i < 3; i++ {
+		l.Trace("flood")
+	}
+	assert.Equal(t, 3, logs.Len())
+}
+
+func TestNewSampledLogger_Named(t *testing.T) {
+	defer ClearNamedLevel("txmanager")
+	SetNamedLevel("txmanager", zapcore.DebugLevel)
+
+	for name, sample := range map[string]func(*Logger) *Logger{
+		"NewSampledLogger": func(l *Logger) *Logger { return NewSampledLogger(l, 1, 1) },
+		"NewLevelSampledLogger": func(l *Logger) *Logger {
+			return NewLevelSampledLogger(l, map[zapcore.Level]SamplingPolicy{zapcore.DebugLevel: {Initial: 1}})
+		},
+		"NewKeySampledLogger": func(l *Logger) *Logger { return NewKeySampledLogger(l, "sampling_key", 1, 1) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewWriterLogger(&buf, zapcore.InfoLevel, true)
+			l := sample(w.Named("txmanager"))
+			other := sample(w.Named("other"))
+
+			assert.True(t, l.DebugEnabled())
+			assert.False(t, other.DebugEnabled())
+			before := testutil.ToFloat64(getCounters().debugLines)
+			l.Named("broadcaster").Debugw("sampled")
+			other.Debugw("other name")
+			assert.Equal(t, before+1, testutil.ToFloat64(getCounters().debugLines))
+			require.NoError(t, l.Sync())
+			assert.Contains(t, buf.String(), `"logger":"txmanager.broadcaster"`)
+			assert.Contains(t, buf.String(), `"msg":"sampled"`)
+			assert.NotContains(t, buf.String(), `"msg":"other name"`)
+		})
+	}
+}
+
+func TestNewKeySampledLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewKeySampledLogger(NewLogger(zap.New(core)), "sampling_key", 1, 0)
+
+	for i := 0; i < 5; i++ {
+		l.Errorw("job failed", "sampling_key", "job-1", "i", i)
+	}
+	l.Errorw("job failed", "sampling_key", "job-2")
+	l.With("sampling_key", 3).Errorw("job failed")
+	l.With("sampling_key", 3).Errorw("job failed")
+	l.Errorw("job failed")
+
+	var keys []interface{}
+	for _, entry := range logs.All() {
+		keys = append(keys, entry.ContextMap()["sampling_key"])
+	}
+	assert.Equal(t, []interface{}{"job-1", "job-2", int64(3), nil}, keys)
+}
+
+func TestNewKeySampledLogger_WriteError(t *testing.T) {
+	l := NewKeySampledLogger(NewWriterLogger(failingWriter{}, zapcore.InfoLevel, true), "sampling_key", 1, 0)
+	ce := l.Desugar().Core().Check(zapcore.Entry{Level: zapcore.ErrorLevel, Message: "job failed"}, nil)
+	require.NotNil(t, ce)
+
+	var errorOutput bytes.Buffer
+	ce.ErrorOutput = zapcore.AddSync(&errorOutput)
+	ce.Write(zap.String("sampling_key", "job-1"))
+	assert.Equal(t, 1, strings.Count(errorOutput.String(), "write error: disk full"), errorOutput.String())
+
+	var buf bytes.Buffer
+	l = NewKeySampledLogger(NewWriterLogger(&buf, zapcore.InfoLevel, true), "sampling_key", 1, 0)
+	l.Errorw("job failed", "sampling_key", "job-1")
+	assert.Contains(t, buf.String(), `"msg":"job failed"`, "the entries should still be written through the cores of the package")
+}
+
+func TestKeySamplingState(t *testing.T) {
+	s := &keySamplingState{first: 2, thereafter: 3, counts: map[keySamplingKey]int{}}
+	now := time.Now()
+
+	var sampled []int
+	for i := 0; i < 10; i++ {
+		if s.sample(keySamplingKey{msg: "flood"}, now) {
+			sampled = append(sampled, i)
+		}
+	}
+	assert.Equal(t, []int{0, 1, 4, 7}, sampled)
+	assert.True(t, s.sample(keySamplingKey{msg: "flood"}, now.Add(samplingTick)), "counts are reset every tick")
+}
+
+func TestNewLevelSampledLogger(t *testing.T) {
+	core, logs := observer.New(TraceLevel)
+	l := NewLevelSampledLogger(NewLogger(zap.New(core)), map[zapcore.Level]SamplingPolicy{
+		zapcore.InfoLevel:  {Initial: 2, Thereafter: 0},
+		zapcore.DebugLevel: {Initial: 1, Thereafter: 2},
+		TraceLevel:         {Initial: 1, Thereafter: 0},
+	})
+
+	dropped := testutil.ToFloat64(getCounters().droppedLines.WithLabelValues("info"))
+	infos := testutil.ToFloat64(getCounters().infoLines)
+	for i := 0; i < 5; i++ {
+		l.Info("flood")
+		l.With("i", i).Debug("flood")
+		l.Error("flood")
+		l.Trace("flood")
+	}
+
+	counts := map[zapcore.Level]int{}
+	for _, entry := range logs.All() {
+		counts[entry.Level]++
+	}
+	assert.Equal(t, 2, counts[zapcore.InfoLevel])
+	assert.Equal(t, 3, counts[zapcore.DebugLevel], "the first entry, then every second one")
+	assert.Equal(t, 5, counts[zapcore.ErrorLevel], "levels without a policy are not sampled")
+	assert.Equal(t, 5, counts[TraceLevel], "trace entries are never sampled")
+	assert.Equal(t, dropped+3, testutil.ToFloat64(getCounters().droppedLines.WithLabelValues("info")))
+	assert.Equal(t, infos+5, testutil.ToFloat64(getCounters().infoLines), "lines are counted before sampling")
+}
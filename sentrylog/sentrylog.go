@@ -0,0 +1,108 @@
+// Package sentrylog forwards entries logged through the logger package to
+// Sentry, in addition to the regular console and disk outputs.
+package sentrylog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/smartcontractkit/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// flushTimeout bounds how long Sync, and entries about to panic or exit,
+// wait for queued events to be delivered to Sentry.
+const flushTimeout = 5 * time.Second
+
+// EnableSentry tees the package logger so that entries at the error level
+// and above are also reported to the Sentry project of the given DSN.
+func EnableSentry(dsn string, environment string) error {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         dsn,
+		Environment: environment,
+	})
+	if err != nil {
+		return err
+	}
+	c := NewCore(sentry.NewHub(client, sentry.NewScope()), zapcore.ErrorLevel)
+
+	logger.WrapPackageCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, c)
+	})
+	return nil
+}
+
+// NewCore returns a zapcore.Core capturing the entries enabled by enab as
+// events of the given hub. Structured fields are attached as extra data,
+// and the TraceID field, when present, as a tag.
+func NewCore(hub *sentry.Hub, enab zapcore.LevelEnabler) zapcore.Core {
+	return &core{LevelEnabler: enab, hub: hub}
+}
+
+type core struct {
+	zapcore.LevelEnabler
+	hub    *sentry.Hub
+	fields []zapcore.Field
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(clone.fields[:len(clone.fields):len(clone.fields)], fields...)
+	return &clone
+}
+
+func (c *core) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	event := sentry.NewEvent()
+	event.Level = sentryLevel(entry.Level)
+	event.Message = entry.Message
+	event.Timestamp = entry.Time
+	event.Logger = entry.LoggerName
+	event.Extra = enc.Fields
+	if traceID, ok := enc.Fields[logger.TraceId]; ok {
+		event.Tags[logger.TraceId] = fmt.Sprint(traceID)
+	}
+	event.Threads = []sentry.Thread{{Stacktrace: sentry.NewStacktrace(), Current: true}}
+
+	c.hub.CaptureEvent(event)
+	if entry.Level > zapcore.ErrorLevel {
+		// The process may be about to panic or exit, don't lose the event.
+		c.hub.Flush(flushTimeout)
+	}
+	return nil
+}
+
+func (c *core) Sync() error {
+	c.hub.Flush(flushTimeout)
+	return nil
+}
+
+func sentryLevel(lvl zapcore.Level) sentry.Level {
+	switch lvl {
+	case zapcore.DebugLevel:
+		return sentry.LevelDebug
+	case zapcore.InfoLevel:
+		return sentry.LevelInfo
+	case zapcore.WarnLevel:
+		return sentry.LevelWarning
+	case zapcore.ErrorLevel:
+		return sentry.LevelError
+	default:
+		return sentry.LevelFatal
+	}
+}
@@ -0,0 +1,79 @@
+package sentrylog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/smartcontractkit/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewCore(t *testing.T) {
+	transport := &testTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: transport})
+	require.NoError(t, err)
+	c := NewCore(sentry.NewHub(client, sentry.NewScope()), zapcore.ErrorLevel)
+	l := logger.NewLogger(zap.New(c))
+
+	l.Infow("ignored")
+	l.With("TraceID", "abc").Errorw("reported", "key", "value")
+	require.NoError(t, l.Sync())
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "reported", events[0].Message)
+	assert.Equal(t, sentry.LevelError, events[0].Level)
+	assert.Equal(t, map[string]interface{}{"TraceID": "abc", "key": "value"}, events[0].Extra)
+	assert.Equal(t, "abc", events[0].Tags["TraceID"])
+	require.Len(t, events[0].Threads, 1)
+	assert.NotEmpty(t, events[0].Threads[0].Stacktrace.Frames)
+}
+
+func TestEnableSentry_KeepsSinks(t *testing.T) {
+	defer logger.SetLogLevel(logger.GetLogLevel())
+	defer logger.ReplaceLogger(logger.GetLogger())
+	dir, err := ioutil.TempDir("", "sentrylog")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l, err := logger.NewProductionLogger(dir, true, zapcore.InfoLevel, true)
+	require.NoError(t, err)
+	logger.ReplaceLogger(l)
+	// Without a DSN, the client discards its events.
+	require.NoError(t, EnableSentry("", "test"))
+	logger.Infow("logged to disk")
+
+	require.NoError(t, logger.Close())
+	b, err := ioutil.ReadFile(filepath.Join(dir, "log.jsonl"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"msg":"logged to disk"`)
+	assert.Error(t, logger.Sync(), "the log file should be closed")
+}
+
+type testTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (*testTransport) Configure(sentry.ClientOptions) {}
+func (*testTransport) Flush(time.Duration) bool       { return true }
+
+func (tt *testTransport) SendEvent(event *sentry.Event) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	tt.events = append(tt.events, event)
+}
+
+func (tt *testTransport) Events() []*sentry.Event {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	return tt.events
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var (
+	// signalFlushMu guards signalFlushSignals.
+	signalFlushMu sync.Mutex
+	// signalFlushSignals are the signals InstallSignalFlush was called for.
+	signalFlushSignals = map[os.Signal]bool{}
+)
+
+// InstallSignalFlush flushes and closes the package logger, see GetLogger,
+// and flushes the audit trail, when the process receives one of the given
+// signals, SIGTERM and SIGINT by default, so that the entries buffered by
+// its outputs are not lost when Kubernetes stops the process. Calling it
+// again for the same signals has no effect.
+//
+// The handlers registered with signal.Notify for those signals still receive
+// them. Once the logs are flushed, the signal is raised again, so that the
+// process terminates as it would without the handler, unless the signal is
+// handled elsewhere; the handlers registered with signal.Notify then receive
+// it twice. The package logger must not be used after the signal, as its
+// outputs are closed: an application shutting down gracefully would rather
+// close the logger itself, once done.
+func InstallSignalFlush(signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	}
+
+	signalFlushMu.Lock()
+	defer signalFlushMu.Unlock()
+	var installed []os.Signal
+	for _, sig := range signals {
+		if !signalFlushSignals[sig] {
+			signalFlushSignals[sig] = true
+			installed = append(installed, sig)
+		}
+	}
+	if len(installed) == 0 {
+		return
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, installed...)
+	go func() {
+		sig := <-c
+		flushOnSignal()
+		signal.Stop(c)
+		if p, err := os.FindProcess(os.Getpid()); err == nil {
+			// Raising the signal is not supported on every platform, in
+			// which case the process is left running, as it would be with
+			// any other handler.
+			_ = p.Signal(sig)
+		}
+	}()
+}
+
+// flushOnSignal flushes and closes the package logger, and flushes the
+// audit trail.
+func flushOnSignal() {
+	// The errors have nowhere left to be reported to.
+	_ = GetLogger().Close()
+	auditMu.RLock()
+	_ = auditLogger.Sync()
+	auditMu.RUnlock()
+}
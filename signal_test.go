@@ -0,0 +1,109 @@
+// +build !windows
+
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// signalSyncer reports its syncs on synced.
+type signalSyncer struct {
+	synced chan struct{}
+}
+
+func (s signalSyncer) Write(b []byte) (int, error) { return len(b), nil }
+
+func (s signalSyncer) Sync() error {
+	s.synced <- struct{}{}
+	return nil
+}
+
+func TestInstallSignalFlush(t *testing.T) {
+	defer SetLogger(GetLogger().Desugar())
+	defer func() {
+		signalFlushMu.Lock()
+		delete(signalFlushSignals, syscall.SIGUSR2)
+		signalFlushMu.Unlock()
+	}()
+	ws := signalSyncer{synced: make(chan struct{}, 1)}
+	SetLogger(zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), ws, zapcore.InfoLevel)))
+
+	// The application's own handler, which keeps the raised signal from
+	// terminating the test.
+	app := make(chan os.Signal, 2)
+	signal.Notify(app, syscall.SIGUSR2)
+	defer signal.Stop(app)
+
+	InstallSignalFlush(syscall.SIGUSR2)
+	InstallSignalFlush(syscall.SIGUSR2)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+
+	select {
+	case <-ws.synced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the package logger was not flushed")
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case sig := <-app:
+			assert.Equal(t, syscall.SIGUSR2, sig)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the signal was not passed on")
+		}
+	}
+	select {
+	case <-ws.synced:
+		t.Fatal("installing the flush again should have no effect")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestInstallSignalFlush_ClosesSinks(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	defer ReplaceLogger(GetLogger())
+	defer func() {
+		signalFlushMu.Lock()
+		delete(signalFlushSignals, syscall.SIGUSR1)
+		signalFlushMu.Unlock()
+	}()
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l, err := NewProductionLogger(dir, true, zapcore.InfoLevel, true, WithBufferedWrites(4096, time.Hour))
+	require.NoError(t, err)
+	ReplaceLogger(l)
+	Infow("buffered until the signal")
+
+	app := make(chan os.Signal, 2)
+	signal.Notify(app, syscall.SIGUSR1)
+	defer signal.Stop(app)
+
+	InstallSignalFlush(syscall.SIGUSR1)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	// The signal is raised again once the sinks are released.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-app:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the signal was not passed on")
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "log.jsonl"))
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(b), `"msg":"buffered until the signal"`), string(b))
+	assert.True(t, l.isClosed(), "the sinks should be released")
+}
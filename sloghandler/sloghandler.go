@@ -0,0 +1,174 @@
+// Package sloghandler bridges log/slog to the logger package: the records
+// logged through a *slog.Logger using its Handler are logged through a
+// logger.Logger, and so counted, redacted and written to its outputs.
+//
+// It is a separate module, as log/slog requires a more recent Go than the
+// logger package.
+package sloghandler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/smartcontractkit/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// callerSkip skips the frames between the caller of a *slog.Logger method
+// and the Logger method Handle calls: Handle itself, and the log method and
+// public method of the *slog.Logger.
+const callerSkip = 3
+
+// Handler is a slog.Handler logging records through a logger.Logger.
+type Handler struct {
+	l *logger.Logger
+}
+
+var _ slog.Handler = Handler{}
+
+// NewHandler returns a Handler logging through l, for slog.New(NewHandler(l)).
+// Slog levels map to the logger's levels rounding down, so that those below
+// slog.LevelDebug are logged at logger.TraceLevel, and attributes map to
+// fields, groups to nested objects. The trace and span IDs of the span in
+// the context of records, if any, are logged along, as with WithContext.
+//
+// Entries are reported as logged by the callers of the *slog.Logger, unless
+// other handlers wrap this one.
+func NewHandler(l *logger.Logger) Handler {
+	return Handler{l: l.WithCallerSkip(callerSkip)}
+}
+
+// Enabled reports whether the logger writes entries at the level.
+func (h Handler) Enabled(_ context.Context, level slog.Level) bool {
+	switch lvl := zapLevel(level); lvl {
+	case zapcore.DebugLevel:
+		return h.l.DebugEnabled()
+	case zapcore.InfoLevel:
+		return h.l.InfoEnabled()
+	case zapcore.WarnLevel:
+		return h.l.WarnEnabled()
+	case zapcore.ErrorLevel:
+		return h.l.ErrorEnabled()
+	default:
+		return h.l.Desugar().Core().Enabled(lvl)
+	}
+}
+
+// Handle logs r through the logger, with the method of its level.
+func (h Handler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make([]interface{}, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendFields(fields, a)
+		return true
+	})
+
+	l := h.l.WithContext(ctx)
+	switch zapLevel(r.Level) {
+	case logger.TraceLevel:
+		l.Tracew(r.Message, fields...)
+	case zapcore.DebugLevel:
+		l.Debugw(r.Message, fields...)
+	case zapcore.InfoLevel:
+		l.Infow(r.Message, fields...)
+	case zapcore.WarnLevel:
+		l.Warnw(r.Message, fields...)
+	default:
+		l.Errorw(r.Message, fields...)
+	}
+	return nil
+}
+
+// WithAttrs returns a Handler adding attrs to every record.
+func (h Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]interface{}, 0, len(attrs))
+	for _, a := range attrs {
+		fields = appendFields(fields, a)
+	}
+	if len(fields) == 0 {
+		return h
+	}
+	return Handler{l: h.l.With(fields...)}
+}
+
+// WithGroup returns a Handler nesting the attributes added afterwards, to
+// it or to records, in an object under name.
+func (h Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return Handler{l: h.l.With(zap.Namespace(name))}
+}
+
+// zapLevel returns the level of the logger a slog level maps to.
+func zapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return logger.TraceLevel
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+// appendFields appends the fields of a to fields: a single one, unless a is
+// a group without a key, whose attributes are inlined as slog does, or is
+// empty, as attributes and groups are ignored then.
+func appendFields(fields []interface{}, a slog.Attr) []interface{} {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return append(fields, zap.String(a.Key, a.Value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(a.Key, a.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(a.Key, a.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(a.Key, a.Value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(a.Key, a.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(a.Key, a.Value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(a.Key, a.Value.Time()))
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			for _, member := range attrs {
+				fields = appendFields(fields, member)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(a.Key, group(attrs)))
+	default:
+		if err, ok := a.Value.Any().(error); ok {
+			return append(fields, zap.NamedError(a.Key, err))
+		}
+		return append(fields, zap.Any(a.Key, a.Value.Any()))
+	}
+}
+
+// group marshals the attributes of a group as the fields of an object.
+type group []slog.Attr
+
+func (g group) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []interface{}
+	for _, a := range g {
+		fields = appendFields(fields, a)
+	}
+	for _, f := range fields {
+		f.(zap.Field).AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package sloghandler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/smartcontractkit/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newLogger(lvl zapcore.Level) (*logger.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(lvl)
+	return logger.NewLogger(zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))), logs
+}
+
+func TestHandler(t *testing.T) {
+	l, logs := newLogger(logger.TraceLevel)
+	sl := slog.New(NewHandler(l))
+
+	_, file, line, _ := runtime.Caller(0)
+	sl.Info("info", "key", "value", "attempt", 3)
+	sl.Log(context.Background(), slog.LevelDebug-1, "trace")
+	sl.Debug("debug")
+	sl.Warn("warn", slog.Duration("elapsed", time.Second))
+	sl.Error("error", "err", errors.New("boom"))
+	sl.Log(context.Background(), slog.LevelError+4, "above error")
+
+	entries := logs.All()
+	require.Len(t, entries, 6)
+	assert.Equal(t, file, entries[0].Caller.File)
+	assert.Equal(t, line+1, entries[0].Caller.Line)
+	assert.Equal(t, map[string]interface{}{"key": "value", "attempt": int64(3)}, entries[0].ContextMap())
+
+	var levels []zapcore.Level
+	for _, e := range entries {
+		levels = append(levels, e.Level)
+	}
+	assert.Equal(t, []zapcore.Level{
+		zapcore.InfoLevel, logger.TraceLevel, zapcore.DebugLevel, zapcore.WarnLevel, zapcore.ErrorLevel, zapcore.ErrorLevel,
+	}, levels)
+	assert.Equal(t, time.Second, entries[3].ContextMap()["elapsed"])
+	assert.Equal(t, "boom", entries[4].ContextMap()["err"])
+}
+
+func TestHandler_Enabled(t *testing.T) {
+	l, logs := newLogger(zapcore.WarnLevel)
+	h := NewHandler(l)
+
+	assert.False(t, h.Enabled(context.Background(), slog.LevelDebug-1))
+	assert.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelWarn))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelError))
+	slog.New(h).Info("filtered")
+	assert.Zero(t, logs.Len())
+}
+
+func TestHandler_AttrsAndGroups(t *testing.T) {
+	l, logs := newLogger(zapcore.InfoLevel)
+	sl := slog.New(NewHandler(l)).With("service", "node").WithGroup("request").With("id", 7)
+
+	sl.Info("grouped",
+		slog.Group("peer", "addr", "10.0.0.1"),
+		slog.Group("", "inlined", true),
+		slog.Group("empty"),
+		slog.Attr{},
+	)
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, map[string]interface{}{
+		"service": "node",
+		"request": map[string]interface{}{
+			"id":      int64(7),
+			"peer":    map[string]interface{}{"addr": "10.0.0.1"},
+			"inlined": true,
+		},
+	}, logs.All()[0].ContextMap())
+}
+
+func TestHandler_Context(t *testing.T) {
+	l, logs := newLogger(zapcore.InfoLevel)
+	traceID := trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{0x01}})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	slog.New(NewHandler(l)).InfoContext(ctx, "traced")
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, traceID.String(), logs.All()[0].ContextMap()[logger.TraceId])
+}
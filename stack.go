@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"runtime"
+
+	"go.uber.org/zap"
+)
+
+// WithStack returns a child logger that adds the stacks of every goroutine,
+// as dumped when WithStack is called, to every subsequent entry under the
+// stack field. It is meant to attach a dump to a single entry, as in
+// l.WithStack().Infow("waiting for lock"), when debugging deadlocks, without
+// enabling stack traces for every error.
+func (l *Logger) WithStack() *Logger {
+	return l.child(l.SugaredLogger.With(zap.ByteString("stack", goroutineDump())))
+}
+
+// goroutineDump returns the stacks of every goroutine, as runtime.Stack
+// formats them.
+func goroutineDump() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger_WithStack(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := NewLogger(zap.New(core))
+
+	blocked := make(chan struct{})
+	defer close(blocked)
+	go func() { <-blocked }()
+
+	l.WithStack().Infow("dumped", "key", "value")
+	l.Info("plain")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	stack, ok := entries[0].ContextMap()["stack"].(string)
+	require.True(t, ok)
+	assert.Contains(t, stack, "logger.TestLogger_WithStack")
+	assert.Contains(t, stack, "logger.TestLogger_WithStack.func1", "every goroutine is dumped")
+	assert.Equal(t, "value", entries[0].ContextMap()["key"])
+	assert.NotContains(t, entries[1].ContextMap(), "stack")
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// stackdriverEncodingPrefix prefixes, in the Encoding of a zap.Config, the
+// ID of the GCP project of the stackdriver encoding.
+const stackdriverEncodingPrefix = "stackdriver:"
+
+// The keys of the special fields of the GCP Cloud Logging structured format.
+const (
+	stackdriverTraceKey          = "logging.googleapis.com/trace"
+	stackdriverSpanIDKey         = "logging.googleapis.com/spanId"
+	stackdriverSourceLocationKey = "logging.googleapis.com/sourceLocation"
+)
+
+var stackdriverSeverities = map[zapcore.Level]string{
+	TraceLevel:          "DEBUG",
+	zapcore.DebugLevel:  "DEBUG",
+	zapcore.InfoLevel:   "INFO",
+	zapcore.WarnLevel:   "WARNING",
+	zapcore.ErrorLevel:  "ERROR",
+	zapcore.DPanicLevel: "CRITICAL",
+	zapcore.PanicLevel:  "ALERT",
+	zapcore.FatalLevel:  "EMERGENCY",
+}
+
+// WithStackdriverEncoding encodes entries in the structured format of GCP
+// Cloud Logging, so that they are parsed as such when written to stdout or
+// stderr on GKE: the level as the severity, the message as the message,
+// the caller as the source location, and the TraceID and SpanID fields as
+// the trace, qualified by the given project ID, and span of the entry.
+//
+// The pretty console can't read this format, so it is meant for loggers
+// with a JSON console. In a config file, it is the "stackdriver:PROJECT_ID"
+// encoding.
+func WithStackdriverEncoding(projectID string) Option {
+	return func(config *zap.Config) {
+		config.Encoding = stackdriverEncodingPrefix + projectID
+	}
+}
+
+// stackdriverEncoder is a JSON encoder writing the special fields of GCP
+// Cloud Logging.
+type stackdriverEncoder struct {
+	zapcore.Encoder
+	projectID string
+}
+
+func newStackdriverEncoder(config zapcore.EncoderConfig, projectID string) zapcore.Encoder {
+	config.TimeKey = "time"
+	config.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	config.LevelKey = "severity"
+	config.EncodeLevel = encodeStackdriverSeverity
+	config.MessageKey = "message"
+	// The caller is written as the source location instead.
+	config.CallerKey = zapcore.OmitKey
+	return &stackdriverEncoder{Encoder: zapcore.NewJSONEncoder(config), projectID: projectID}
+}
+
+func encodeStackdriverSeverity(lvl zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	severity, ok := stackdriverSeverities[lvl]
+	if !ok {
+		severity = "DEFAULT"
+	}
+	enc.AppendString(severity)
+}
+
+func (e *stackdriverEncoder) Clone() zapcore.Encoder {
+	return &stackdriverEncoder{Encoder: e.Encoder.Clone(), projectID: e.projectID}
+}
+
+// AddString also writes the TraceID and SpanID fields added with With as
+// the trace and span of the entries.
+func (e *stackdriverEncoder) AddString(key, value string) {
+	e.Encoder.AddString(key, value)
+	switch key {
+	case TraceId:
+		e.Encoder.AddString(stackdriverTraceKey, e.trace(value))
+	case SpanId:
+		e.Encoder.AddString(stackdriverSpanIDKey, value)
+	}
+}
+
+func (e *stackdriverEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	fields = fields[:len(fields):len(fields)]
+	for _, f := range fields {
+		if f.Type != zapcore.StringType {
+			continue
+		}
+		switch f.Key {
+		case TraceId:
+			fields = append(fields, zap.String(stackdriverTraceKey, e.trace(f.String)))
+		case SpanId:
+			fields = append(fields, zap.String(stackdriverSpanIDKey, f.String))
+		}
+	}
+	if entry.Caller.Defined {
+		fields = append(fields, zap.Object(stackdriverSourceLocationKey, stackdriverSourceLocation(entry.Caller)))
+	}
+	return e.Encoder.EncodeEntry(entry, fields)
+}
+
+// trace qualifies a trace ID with the project ID, as Cloud Logging expects.
+func (e *stackdriverEncoder) trace(traceID string) string {
+	return "projects/" + e.projectID + "/traces/" + traceID
+}
+
+type stackdriverSourceLocation zapcore.EntryCaller
+
+func (c stackdriverSourceLocation) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("file", c.File)
+	// Cloud Logging reads the line as a string.
+	enc.AddString("line", strconv.Itoa(c.Line))
+	if c.Function != "" {
+		enc.AddString("function", c.Function)
+	}
+	return nil
+}
+
+// stackdriverProjectID returns the project ID of a stackdriver encoding.
+func stackdriverProjectID(encoding string) (string, bool) {
+	if !strings.HasPrefix(encoding, stackdriverEncodingPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(encoding, stackdriverEncodingPrefix), true
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestStackdriverEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := newStackdriverEncoder(zap.NewProductionEncoderConfig(), "my-project")
+	l := NewLogger(zap.New(zapcore.NewCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel), zap.AddCaller(), zap.AddCallerSkip(1)))
+
+	decode := func() map[string]interface{} {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		buf.Reset()
+		return entry
+	}
+
+	l.With(TraceId, "4bf92f3577b34da6a3ce929d0e0e4736").Warnw("traced", SpanId, "00f067aa0ba902b7", "key", "value")
+	entry := decode()
+	assert.Equal(t, "WARNING", entry["severity"])
+	assert.Equal(t, "traced", entry["message"])
+	assert.Contains(t, entry, "time")
+	assert.Equal(t, "value", entry["key"])
+	assert.Equal(t, "projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736", entry["logging.googleapis.com/trace"])
+	assert.Equal(t, "00f067aa0ba902b7", entry["logging.googleapis.com/spanId"])
+	location := entry["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+	assert.Contains(t, location["file"], "stackdriver_test.go")
+	assert.NotEmpty(t, location["line"])
+	assert.Contains(t, location["function"], "TestStackdriverEncoder")
+
+	l.Infow("traced by field", TraceId, "abc")
+	entry = decode()
+	assert.Equal(t, "INFO", entry["severity"])
+	assert.Equal(t, "projects/my-project/traces/abc", entry["logging.googleapis.com/trace"])
+
+	l.Errorw("untraced")
+	entry = decode()
+	assert.Equal(t, "ERROR", entry["severity"])
+	assert.NotContains(t, entry, "logging.googleapis.com/trace")
+}
+
+func TestWithStackdriverEncoding(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	dir, err := ioutil.TempDir("", "logger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.jsonl")
+
+	config := productionConfig(true, zapcore.InfoLevel)
+	config.OutputPaths = []string{path}
+	applyOptions(&config, []Option{WithStackdriverEncoding("my-project")})
+	assert.Equal(t, "stackdriver:my-project", config.Encoding)
+	l, err := buildLogger(config)
+	require.NoError(t, err)
+	l.Infow("encoded", TraceId, "abc")
+	require.NoError(t, l.Close())
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"severity":"INFO"`)
+	assert.Contains(t, string(b), `"logging.googleapis.com/trace":"projects/my-project/traces/abc"`)
+}
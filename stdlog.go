@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"log"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// stdLogCallerSkip skips the frames between the callers of the log package
+// and the Logger: the log method called, such as Printf, the output method
+// it calls, and levelWriter.Write.
+const stdLogCallerSkip = 3
+
+// StdLogger returns a standard library *log.Logger writing every line
+// through l at lvl, for packages taking one, such as http.Server.ErrorLog.
+// As with Write, the trailing newline added by the log package is dropped,
+// and lines are counted at lvl. At the panic and fatal levels, lines panic
+// or exit as the methods of l do.
+func (l *Logger) StdLogger(lvl zapcore.Level) *log.Logger {
+	return log.New(levelWriter{l: l.WithCallerSkip(stdLogCallerSkip), lvl: lvl}, "", 0)
+}
+
+// levelWriter logs the bytes written to it as messages at lvl.
+type levelWriter struct {
+	l   *Logger
+	lvl zapcore.Level
+}
+
+func (w levelWriter) Write(b []byte) (int, error) {
+	msg := strings.TrimSuffix(string(b), "\n")
+	switch w.lvl {
+	case TraceLevel:
+		w.l.Trace(msg)
+	case zapcore.DebugLevel:
+		w.l.Debug(msg)
+	case zapcore.InfoLevel:
+		w.l.Info(msg)
+	case zapcore.WarnLevel:
+		w.l.Warn(msg)
+	case zapcore.ErrorLevel:
+		w.l.Error(msg)
+	case zapcore.DPanicLevel:
+		w.l.DPanic(msg)
+	case zapcore.PanicLevel:
+		w.l.Panic(msg)
+	case zapcore.FatalLevel:
+		w.l.Fatal(msg)
+	default:
+		w.l.Info(msg)
+	}
+	return len(b), nil
+}
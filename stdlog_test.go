@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger_StdLogger(t *testing.T) {
+	core, logs := observer.New(TraceLevel)
+	l := NewLogger(zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1)))
+
+	before := testutil.ToFloat64(getCounters().warnLines)
+	stdlog := l.StdLogger(zapcore.WarnLevel)
+	_, file, line, _ := runtime.Caller(0)
+	stdlog.Printf("line %d", 1)
+	stdlog.Println("line", 2)
+	l.StdLogger(TraceLevel).Print("traced")
+
+	assert.Equal(t, before+2, testutil.ToFloat64(getCounters().warnLines))
+	entries := logs.All()
+	require.Len(t, entries, 3)
+	assert.Equal(t, "line 1", entries[0].Message)
+	assert.Equal(t, "line 2", entries[1].Message)
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	assert.Equal(t, file, entries[0].Caller.File)
+	assert.Equal(t, line+1, entries[0].Caller.Line)
+	assert.Equal(t, line+2, entries[1].Caller.Line)
+	assert.Equal(t, TraceLevel, entries[2].Level)
+}
+
+func TestLogger_StdLogger_Panic(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := NewLogger(zap.New(core))
+
+	assert.Panics(t, func() { l.StdLogger(zapcore.PanicLevel).Print("panicked") })
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, zapcore.PanicLevel, logs.All()[0].Level)
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	"go.uber.org/multierr"
+)
+
+// Sync flushes any buffered log entries. The errors syncing stdout or
+// stderr returns on many platforms when they are a terminal or a pipe are
+// ignored, while those of the other sinks, such as files, are returned.
+func (l *Logger) Sync() error {
+	return filterConsoleSyncErrors(l.SugaredLogger.Sync())
+}
+
+// filterConsoleSyncErrors drops the console sync errors from err, which may
+// combine the errors of several sinks.
+func filterConsoleSyncErrors(err error) error {
+	var errs []error
+	for _, e := range multierr.Errors(err) {
+		if !isConsoleSyncError(e) {
+			errs = append(errs, e)
+		}
+	}
+	return multierr.Combine(errs...)
+}
+
+// isConsoleSyncError reports whether err is stdout or stderr refusing to be
+// synced, as the terminals and pipes they usually are cannot be.
+func isConsoleSyncError(err error) bool {
+	var pathErr *os.PathError
+	if !errors.As(err, &pathErr) {
+		return false
+	}
+	if pathErr.Path != os.Stdout.Name() && pathErr.Path != os.Stderr.Name() {
+		return false
+	}
+	return errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) || errors.Is(err, syscall.EBADF)
+}
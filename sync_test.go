@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLogger_Sync(t *testing.T) {
+	console := func(errno syscall.Errno) zapcore.WriteSyncer {
+		return &testSyncer{err: &os.PathError{Op: "sync", Path: os.Stderr.Name(), Err: errno}}
+	}
+	fileErr := &os.PathError{Op: "sync", Path: "/var/log/log.jsonl", Err: syscall.EINVAL}
+	newLogger := func(ws ...zapcore.WriteSyncer) *Logger {
+		enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+		return NewLogger(zap.New(zapcore.NewCore(enc, zapcore.NewMultiWriteSyncer(ws...), zapcore.InfoLevel)))
+	}
+
+	assert.NoError(t, newLogger(console(syscall.ENOTTY), console(syscall.EINVAL), console(syscall.EBADF)).Sync())
+	assert.Equal(t, fileErr, newLogger(console(syscall.ENOTTY), &testSyncer{err: fileErr}).Sync())
+
+	ioErr := &os.PathError{Op: "sync", Path: os.Stderr.Name(), Err: syscall.EIO}
+	assert.Equal(t, ioErr, newLogger(&testSyncer{err: ioErr}).Sync())
+
+	otherErr := errors.New("flush failed")
+	assert.Equal(t, otherErr, newLogger(&testSyncer{err: otherErr}).Sync())
+}
+
+type testSyncer struct {
+	err error
+}
+
+func (s *testSyncer) Write(b []byte) (int, error) { return len(b), nil }
+func (s *testSyncer) Sync() error                 { return s.err }
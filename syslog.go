@@ -0,0 +1,136 @@
+// +build !windows
+
+package logger
+
+import (
+	"log/syslog"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+var syslogSeverities = map[string]syslog.Priority{
+	"emerg":   syslog.LOG_EMERG,
+	"alert":   syslog.LOG_ALERT,
+	"crit":    syslog.LOG_CRIT,
+	"err":     syslog.LOG_ERR,
+	"warning": syslog.LOG_WARNING,
+	"notice":  syslog.LOG_NOTICE,
+	"info":    syslog.LOG_INFO,
+	"debug":   syslog.LOG_DEBUG,
+}
+
+// levelSeverities maps the lowercased levels of the JSON entries to syslog
+// severities.
+var levelSeverities = map[string]syslog.Priority{
+	"trace":   syslog.LOG_DEBUG,
+	"debug":   syslog.LOG_DEBUG,
+	"info":    syslog.LOG_INFO,
+	"warn":    syslog.LOG_WARNING,
+	"warning": syslog.LOG_WARNING,
+	"error":   syslog.LOG_ERR,
+	"dpanic":  syslog.LOG_CRIT,
+	"panic":   syslog.LOG_ALERT,
+	"fatal":   syslog.LOG_EMERG,
+}
+
+// syslogSink writes each JSON entry to syslog, with the severity matching
+// the level of the entry, or the default priority of the writer when the
+// level is unknown.
+type syslogSink struct {
+	*syslog.Writer
+}
+
+func (s syslogSink) Write(b []byte) (int, error) {
+	msg := strings.TrimSuffix(string(b), "\n")
+	severity, ok := levelSeverities[strings.ToLower(gjson.GetBytes(b, "level").String())]
+	if !ok {
+		if _, err := s.Writer.Write([]byte(msg)); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	var err error
+	switch severity {
+	case syslog.LOG_EMERG:
+		err = s.Emerg(msg)
+	case syslog.LOG_ALERT:
+		err = s.Alert(msg)
+	case syslog.LOG_CRIT:
+		err = s.Crit(msg)
+	case syslog.LOG_ERR:
+		err = s.Err(msg)
+	case syslog.LOG_WARNING:
+		err = s.Warning(msg)
+	case syslog.LOG_INFO:
+		err = s.Info(msg)
+	default:
+		err = s.Debug(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (syslogSink) Sync() error { return nil }
+
+// newSyslogSink opens a syslog sink for an output path such as
+// syslog://localhost:514?network=udp&facility=local0&priority=info&tag=app.
+// Without a host it writes to the local syslog daemon. The facility
+// defaults to user, and priority is the severity of entries without a known
+// level, info by default.
+func newSyslogSink(u *url.URL) (zap.Sink, error) {
+	query := u.Query()
+	priority := syslog.LOG_USER | syslog.LOG_INFO
+	if v := query.Get("facility"); v != "" {
+		facility, ok := syslogFacilities[v]
+		if !ok {
+			return nil, errors.Errorf("invalid syslog facility %q", v)
+		}
+		priority = facility | priority&0x07
+	}
+	if v := query.Get("priority"); v != "" {
+		severity, ok := syslogSeverities[v]
+		if !ok {
+			return nil, errors.Errorf("invalid syslog priority %q", v)
+		}
+		priority = priority&^0x07 | severity
+	}
+
+	network := query.Get("network")
+	if network == "" && u.Host != "" {
+		network = "udp"
+	}
+	w, err := syslog.Dial(network, u.Host, priority, query.Get("tag"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to syslog")
+	}
+	return syslogSink{w}, nil
+}
@@ -0,0 +1,65 @@
+// +build !windows
+
+package logger
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	u, err := url.Parse("syslog://" + conn.LocalAddr().String() + "?facility=local0&priority=notice&tag=test")
+	require.NoError(t, err)
+	sink, err := newSyslogSink(u)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	read := func() string {
+		buf := make([]byte, 1024)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	tests := []struct {
+		entry string
+		pri   string
+	}{
+		{`{"level":"error","msg":"failed"}`, "<131>"},
+		{`{"level":"warn","msg":"warned"}`, "<132>"},
+		{`{"level":"WARNING","msg":"warned"}`, "<132>"},
+		{`{"level":"debug","msg":"debugged"}`, "<135>"},
+		{`not json`, "<133>"},
+	}
+	for _, tt := range tests {
+		n, err := sink.Write([]byte(tt.entry + "\n"))
+		require.NoError(t, err)
+		assert.Equal(t, len(tt.entry)+1, n)
+		msg := read()
+		assert.Regexp(t, `^`+tt.pri, msg)
+		assert.Contains(t, msg, "test[")
+		assert.Contains(t, msg, tt.entry)
+	}
+}
+
+func TestNewSyslogSink_Invalid(t *testing.T) {
+	u, err := url.Parse("syslog://localhost?facility=bogus")
+	require.NoError(t, err)
+	_, err = newSyslogSink(u)
+	assert.EqualError(t, err, `invalid syslog facility "bogus"`)
+
+	u, err = url.Parse("syslog://localhost?priority=loud")
+	require.NoError(t, err)
+	_, err = newSyslogSink(u)
+	assert.EqualError(t, err, `invalid syslog priority "loud"`)
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// TraceLevel is below zap's levels, for diagnostic output even more verbose
+// than the debug level. It is logged by the Trace methods, and enabled by
+// SetLogLevel(TraceLevel) or LOG_LEVEL=trace.
+const TraceLevel = zapcore.DebugLevel - 1
+
+// levelName returns the lowercase name of lvl, trace included.
+func levelName(lvl zapcore.Level) string {
+	if lvl == TraceLevel {
+		return "trace"
+	}
+	return lvl.String()
+}
+
+// parseLevel parses the name of a level, trace included, ignoring case.
+func parseLevel(text string) (zapcore.Level, error) {
+	if strings.EqualFold(text, "trace") {
+		return TraceLevel, nil
+	}
+	var lvl zapcore.Level
+	err := lvl.UnmarshalText([]byte(text))
+	return lvl, err
+}
+
+// withTraceLevel wraps enc to encode TraceLevel, which zap's level encoders
+// don't know of, by its name, in the case enc encodes the debug level in.
+// Encoders naming TraceLevel themselves, such as those of LevelNameEncoder
+// given a name for it, are left as they are.
+func withTraceLevel(enc zapcore.LevelEncoder) zapcore.LevelEncoder {
+	if enc == nil {
+		return nil
+	}
+	if name := encodedLevel(enc, TraceLevel); !strings.HasPrefix(strings.ToLower(name), "level(") {
+		return enc
+	}
+	trace := "trace"
+	if debug := encodedLevel(enc, zapcore.DebugLevel); debug == strings.ToUpper(debug) {
+		trace = "TRACE"
+	}
+	return func(lvl zapcore.Level, pae zapcore.PrimitiveArrayEncoder) {
+		if lvl == TraceLevel {
+			pae.AppendString(trace)
+			return
+		}
+		enc(lvl, pae)
+	}
+}
+
+// encodedLevel returns lvl as encoded by enc, as text.
+func encodedLevel(enc zapcore.LevelEncoder, lvl zapcore.Level) string {
+	obj := zapcore.NewMapObjectEncoder()
+	_ = obj.AddArray("level", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		enc(lvl, arr)
+		return nil
+	}))
+	if encoded, ok := obj.Fields["level"].([]interface{}); ok && len(encoded) == 1 {
+		return fmt.Sprint(encoded[0])
+	}
+	return ""
+}
+
+// LevelNameEncoder returns a level encoder writing the levels by the given
+// names, such as "WARNING" for zapcore.WarnLevel, and the levels missing
+// from names by their lowercase names, as zapcore.LowercaseLevelEncoder
+// does. See WithLevelEncoder.
+func LevelNameEncoder(names map[zapcore.Level]string) zapcore.LevelEncoder {
+	copied := make(map[zapcore.Level]string, len(names))
+	for lvl, name := range names {
+		copied[lvl] = name
+	}
+	return func(lvl zapcore.Level, pae zapcore.PrimitiveArrayEncoder) {
+		if name, ok := copied[lvl]; ok {
+			pae.AppendString(name)
+			return
+		}
+		pae.AppendString(levelName(lvl))
+	}
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogger_Trace(t *testing.T) {
+	core, logs := observer.New(TraceLevel)
+	l := NewLogger(zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1)))
+
+	_, file, line, _ := runtime.Caller(0)
+	l.Tracew("traced", "key", "value")
+	l.Tracef("traced %d", 2)
+	l.Trace("traced ", 3)
+
+	entries := logs.All()
+	require.Len(t, entries, 3)
+	assert.Equal(t, TraceLevel, entries[0].Level)
+	assert.Equal(t, map[string]interface{}{"key": "value"}, entries[0].ContextMap())
+	assert.Equal(t, file, entries[0].Caller.File)
+	assert.Equal(t, line+1, entries[0].Caller.Line)
+	assert.Equal(t, "traced 2", entries[1].Message)
+	assert.Equal(t, "traced 3", entries[2].Message)
+}
+
+func TestLogger_Trace_Disabled(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewLogger(zap.New(core))
+
+	before := testutil.ToFloat64(getCounters().traceLines)
+	l.Tracew("filtered")
+	assert.Equal(t, 0, logs.Len())
+	assert.Equal(t, before, testutil.ToFloat64(getCounters().traceLines))
+}
+
+func TestParseLevel(t *testing.T) {
+	for text, want := range map[string]zapcore.Level{"trace": TraceLevel, "Trace": TraceLevel, "debug": zapcore.DebugLevel, "warn": zapcore.WarnLevel} {
+		lvl, err := parseLevel(text)
+		require.NoError(t, err)
+		assert.Equal(t, want, lvl, text)
+	}
+	_, err := parseLevel("loud")
+	assert.Error(t, err)
+	assert.Equal(t, "trace", levelName(TraceLevel))
+	assert.Equal(t, "info", levelName(zapcore.InfoLevel))
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"io"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewWriterLogger returns a Logger writing entries at lvl and above to w,
+// as JSON if jsonEncoding is set and pretty printed otherwise, such as to
+// a bytes.Buffer capturing the output of a test. Unlike the production
+// constructors, the level is the logger's own, and every entry is written:
+// nothing is sampled. Closing the logger leaves w open.
+func NewWriterLogger(w io.Writer, lvl zapcore.Level, jsonEncoding bool) *Logger {
+	config := zap.NewProductionConfig()
+	config.Level = zap.NewAtomicLevelAt(lvl)
+	config.EncoderConfig.EncodeLevel = withTraceLevel(config.EncoderConfig.EncodeLevel)
+
+	ws := zapcore.AddSync(w)
+	if !jsonEncoding {
+		ws = PrettyConsole{Sink: writerSink{ws}}
+	}
+	levels := namedLevelEnabler{base: config.Level}
+	core := newWriteErrorCountingCore(zapcore.NewCore(zapcore.NewJSONEncoder(config.EncoderConfig), ws, levels))
+
+	zopts := append([]zap.Option{zap.AddCaller()}, buildOptions(config)...)
+	zopts = append(zopts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newNamedLevelCore(core, levels)
+	}))
+	return NewLogger(zap.New(core, zopts...))
+}
+
+// writerSink is the zap.Sink of NewWriterLogger, which closing a logger
+// leaves open.
+type writerSink struct {
+	zapcore.WriteSyncer
+}
+
+func (writerSink) Close() error { return nil }
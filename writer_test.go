@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewWriterLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWriterLogger(&buf, zapcore.InfoLevel, true)
+
+	before := testutil.ToFloat64(getCounters().infoLines)
+	l.Debugw("filtered")
+	l.Infow("captured", "key", "value")
+	require.NoError(t, l.Close())
+	assert.Equal(t, before+1, testutil.ToFloat64(getCounters().infoLines))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "captured", entry["msg"])
+	assert.Equal(t, "value", entry["key"])
+	assert.Contains(t, entry["caller"], "writer_test.go")
+}
+
+func TestNewWriterLogger_Pretty(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWriterLogger(&buf, TraceLevel, false)
+
+	l.Tracew("captured", "key", "value")
+	require.NoError(t, l.Sync())
+	assert.Contains(t, buf.String(), "[TRACE]")
+	assert.Contains(t, buf.String(), "key=value")
+	assert.NotContains(t, buf.String(), "\x1b[")
+}
+
+func TestNewWriterLogger_OwnLevel(t *testing.T) {
+	defer SetLogLevel(GetLogLevel())
+	var buf bytes.Buffer
+	l := NewWriterLogger(&buf, zapcore.WarnLevel, true)
+
+	SetLogLevel(zapcore.DebugLevel)
+	l.Info("filtered")
+	assert.Zero(t, buf.Len())
+}